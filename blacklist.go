@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"p2p-check/src/config"
+	"p2p-check/src/logger"
+	"p2p-check/src/notification"
+	"p2p-check/src/offer"
+)
+
+// blacklistAllMethods : /blacklist method argument banning an advertiser for every payment method
+const blacklistAllMethods = "all"
+
+// blacklistMethodAliases : short /blacklist method arguments
+var blacklistMethodAliases = map[string]string{
+	"line": "LINEPay",
+	"bank": "BANK",
+}
+
+// seenAdvertisers : seenAdvertiserKey -> user identifier of advertisers we got offers from, so that they can be
+// blacklisted by nickname while being matched on their identifier
+var seenAdvertisers = sync.Map{}
+
+// seenAdvertiserKey : the same nickname can belong to different people on different venues
+type seenAdvertiserKey struct {
+	venue    string
+	nickname string
+}
+
+func blackListCallback(cfgManager config.Manager) func(args string) (string, error) {
+	return func(args string) (string, error) {
+		// blacklist {name} {method} [{duration}] [{reason}]
+		cleanArgs := strings.Fields(args)
+		if len(cleanArgs) == 0 {
+			return formatBlacklist(cfgManager.GetConfig().BlackList), nil
+		}
+
+		var blacklist config.Blacklist
+		err := cfgManager.Update(func(c *config.Config) error {
+			entry, err := newBlacklistEntry(c.WithDefaults(), cleanArgs, time.Now())
+			if err != nil {
+				return err
+			}
+
+			c.BlackList = append(c.BlackList, entry)
+			blacklist = c.BlackList
+
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return formatBlacklist(blacklist), nil
+	}
+}
+
+// newBlacklistEntry : entry added at now from the /blacklist arguments {name} {method} [{duration}] [{reason}]. The
+// first argument after the method is only taken as the duration if it parses as one
+func newBlacklistEntry(c config.Config, args []string, now time.Time) (config.BlacklistEntry, error) {
+	if len(args) < 2 {
+		return config.BlacklistEntry{}, errors.New("invalid arguments")
+	}
+
+	methods, err := blacklistMethods(c, args[1])
+	if err != nil {
+		return config.BlacklistEntry{}, err
+	}
+
+	entry := config.BlacklistEntry{
+		AdvertiserRef: advertiserRef(args[0]),
+		Methods:       methods,
+		AddedAt:       now,
+	}
+
+	reasonArgs := args[2:]
+	if len(reasonArgs) > 0 {
+		if duration, ok := parseBanDuration(reasonArgs[0]); ok {
+			expiresAt := now.Add(duration)
+			entry.ExpiresAt = &expiresAt
+			reasonArgs = reasonArgs[1:]
+		}
+	}
+	entry.Reason = strings.Join(reasonArgs, " ")
+
+	return entry, nil
+}
+
+// blacklistMethods : payment method identifiers referred to by a /blacklist method argument. nil means all
+func blacklistMethods(c config.Config, arg string) ([]string, error) {
+	arg = strings.ToLower(arg)
+	if arg == blacklistAllMethods {
+		return nil, nil
+	}
+
+	if identifier, ok := blacklistMethodAliases[arg]; ok {
+		return []string{identifier}, nil
+	}
+
+	for _, m := range c.PaymentMethods {
+		if strings.EqualFold(m.Identifier, arg) {
+			return []string{m.Identifier}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("method '%s' not supported", arg)
+}
+
+func formatBlacklist(blacklist config.Blacklist) string {
+	if len(blacklist) == 0 {
+		return "blacklist is empty"
+	}
+
+	lines := make([]string, 0, len(blacklist))
+	for _, e := range blacklist {
+		methods := blacklistAllMethods
+		if len(e.Methods) > 0 {
+			methods = strings.Join(e.Methods, ",")
+		}
+
+		line := fmt.Sprintf("%s: %s", formatAdvertiserRef(e.AdvertiserRef), methods)
+		if e.ExpiresAt != nil {
+			line += " until " + e.ExpiresAt.Format(time.RFC3339)
+		}
+		if e.Reason != "" {
+			line += " - " + e.Reason
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseBanDuration : duration of a temporary ban, like "12h", "3d" or "1d12h". ok is false if arg is not a positive
+// duration
+func parseBanDuration(arg string) (duration time.Duration, ok bool) {
+	if days, rest, found := strings.Cut(arg, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, false
+		}
+
+		duration = time.Duration(n) * 24 * time.Hour
+		arg = rest
+	}
+
+	if arg != "" {
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return 0, false
+		}
+
+		duration += d
+	}
+
+	return duration, duration > 0
+}
+
+// startBlacklistSweepLoop : remove expired entries from the blacklist, notifying which bans were lifted
+func startBlacklistSweepLoop(
+	ctx context.Context, cfgManager config.Manager, notificationClient notification.Client, errChan chan error,
+) {
+	ticker := time.NewTicker(time.Minute)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := sweepBlacklist(cfgManager, time.Now())
+			if err != nil {
+				errChan <- err
+				continue
+			}
+			if len(expired) == 0 {
+				continue
+			}
+
+			logger.Default.WithField("expired", len(expired)).Info("blacklist entries expired")
+
+			msg := "Blacklist entries expired:\n" + formatBlacklist(expired)
+			if err = notificationClient.SendMessage(msg); err != nil {
+				errChan <- err
+			}
+		}
+	}
+}
+
+// sweepBlacklist : remove the blacklist entries expired at now, returning them. The config is only saved if some
+// entries expired
+func sweepBlacklist(cfgManager config.Manager, now time.Time) (config.Blacklist, error) {
+	if _, expired := cfgManager.GetConfig().BlackList.RemoveExpired(now); len(expired) == 0 {
+		return nil, nil
+	}
+
+	var expired config.Blacklist
+	err := cfgManager.Update(func(c *config.Config) error {
+		c.BlackList, expired = c.BlackList.RemoveExpired(now)
+		return nil
+	})
+
+	return expired, err
+}
+
+// isAdvertiserBlacklisted : true if the advertiser of o is banned for method
+func isAdvertiserBlacklisted(c config.Config, o offer.Offer, method string) bool {
+	return c.BlackList.IsBanned(o.Venue, o.Advertiser.ID, o.Advertiser.Nickname, method)
+}
+
+// rememberAdvertiser : store the identifier of the advertiser of o, see seenAdvertisers
+func rememberAdvertiser(o offer.Offer) {
+	if o.Advertiser.ID != "" && o.Advertiser.Nickname != "" {
+		seenAdvertisers.Store(seenAdvertiserKey{venue: o.Venue, nickname: o.Advertiser.Nickname}, o.Advertiser.ID)
+	}
+}
+
+// advertiserRef : reference to the advertiser with nickname, including their venue and identifier if we got offers
+// from them on a single venue. Otherwise, they can only be matched on their nickname
+func advertiserRef(nickname string) config.AdvertiserRef {
+	ref := config.AdvertiserRef{Nickname: nickname}
+
+	venues := 0
+	seenAdvertisers.Range(func(k, v any) bool {
+		if key := k.(seenAdvertiserKey); key.nickname == nickname {
+			ref.Venue, ref.UserNo = key.venue, v.(string)
+			venues++
+		}
+
+		return true
+	})
+	if venues > 1 {
+		ref.Venue, ref.UserNo = "", ""
+	}
+
+	return ref
+}
+
+func formatAdvertiserRef(ref config.AdvertiserRef) string {
+	if ref.UserNo == "" {
+		return ref.Nickname
+	}
+
+	return fmt.Sprintf("%s (%s %s)", ref.Nickname, ref.Venue, ref.UserNo)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"p2p-check/src/config"
+	"p2p-check/src/offer"
+)
+
+// memoryManager : config.Manager keeping the config in memory
+type memoryManager struct {
+	lock  sync.Mutex
+	cfg   config.Config
+	saves int
+}
+
+func (m *memoryManager) GetConfig() config.Config {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.cfg
+}
+
+func (m *memoryManager) SaveConfig(cfg config.Config) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.cfg = cfg
+	m.saves++
+}
+
+func (m *memoryManager) Update(fn func(cfg *config.Config) error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	cfg := m.cfg
+	if err := fn(&cfg); err != nil {
+		return err
+	}
+	m.cfg = cfg
+	m.saves++
+
+	return nil
+}
+
+func TestParseBanDuration(t *testing.T) {
+	tests := []struct {
+		arg          string
+		wantDuration time.Duration
+		wantOk       bool
+	}{
+		{arg: "12h", wantDuration: 12 * time.Hour, wantOk: true},
+		{arg: "90m", wantDuration: 90 * time.Minute, wantOk: true},
+		{arg: "3d", wantDuration: 72 * time.Hour, wantOk: true},
+		{arg: "1d12h", wantDuration: 36 * time.Hour, wantOk: true},
+		{arg: "-1d", wantOk: false},
+		{arg: "-12h", wantOk: false},
+		{arg: "0d", wantOk: false},
+		// a bare number has no unit
+		{arg: "3", wantOk: false},
+		{arg: "d", wantOk: false},
+		// reason words containing a "d"
+		{arg: "delayed", wantOk: false},
+		{arg: "dead", wantOk: false},
+		{arg: "scammed", wantOk: false},
+		{arg: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			duration, ok := parseBanDuration(tt.arg)
+			if ok != tt.wantOk {
+				t.Fatalf("expected ok %t, got %t (%s)", tt.wantOk, ok, duration)
+			}
+			if ok && duration != tt.wantDuration {
+				t.Errorf("expected %s, got %s", tt.wantDuration, duration)
+			}
+		})
+	}
+}
+
+func TestNewBlacklistEntry(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	c := config.Config{}.WithDefaults()
+
+	tests := []struct {
+		name          string
+		args          []string
+		wantMethods   []string
+		wantExpiresAt time.Time
+		wantReason    string
+		wantErr       bool
+	}{
+		{
+			name:        "permanent without reason",
+			args:        []string{"alice", "line"},
+			wantMethods: []string{"LINEPay"},
+		},
+		{
+			name:       "permanent with reason",
+			args:       []string{"alice", "all", "never", "released"},
+			wantReason: "never released",
+		},
+		{
+			name:          "temporary without reason",
+			args:          []string{"alice", "bank", "1d12h"},
+			wantMethods:   []string{"BANK"},
+			wantExpiresAt: now.Add(36 * time.Hour),
+		},
+		{
+			name:          "temporary with reason",
+			args:          []string{"alice", "all", "12h", "slow", "release"},
+			wantExpiresAt: now.Add(12 * time.Hour),
+			wantReason:    "slow release",
+		},
+		{
+			name:       "reason starting with a d",
+			args:       []string{"alice", "all", "delayed", "release"},
+			wantReason: "delayed release",
+		},
+		{
+			name:       "reason starting with a number",
+			args:       []string{"alice", "all", "3", "cancellations"},
+			wantReason: "3 cancellations",
+		},
+		{
+			name:       "negative duration is part of the reason",
+			args:       []string{"alice", "all", "-1d"},
+			wantReason: "-1d",
+		},
+		{
+			name:    "missing method",
+			args:    []string{"alice"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown method",
+			args:    []string{"alice", "cash"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := newBlacklistEntry(c, tt.args, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", entry)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if entry.Nickname != "alice" || !entry.AddedAt.Equal(now) {
+				t.Errorf("unexpected entry %+v", entry)
+			}
+			if len(entry.Methods) != len(tt.wantMethods) || (len(tt.wantMethods) > 0 && entry.Methods[0] != tt.wantMethods[0]) {
+				t.Errorf("expected methods %v, got %v", tt.wantMethods, entry.Methods)
+			}
+			if entry.Reason != tt.wantReason {
+				t.Errorf("expected reason '%s', got '%s'", tt.wantReason, entry.Reason)
+			}
+			switch {
+			case tt.wantExpiresAt.IsZero() && entry.ExpiresAt != nil:
+				t.Errorf("expected a permanent ban, got one expiring at %s", entry.ExpiresAt)
+			case !tt.wantExpiresAt.IsZero() && (entry.ExpiresAt == nil || !entry.ExpiresAt.Equal(tt.wantExpiresAt)):
+				t.Errorf("expected expiration at %s, got %v", tt.wantExpiresAt, entry.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestBlackListCallback(t *testing.T) {
+	m := &memoryManager{}
+	callback := blackListCallback(m)
+
+	if _, err := callback("alice cash"); err == nil {
+		t.Errorf("expected an error for an unknown method")
+	}
+	if m.saves != 0 {
+		t.Errorf("expected no save on error, got %d", m.saves)
+	}
+
+	if _, err := callback("alice line 3d slow release"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	blacklist := m.GetConfig().BlackList
+	if len(blacklist) != 1 || blacklist[0].Nickname != "alice" || blacklist[0].ExpiresAt == nil || blacklist[0].Reason != "slow release" {
+		t.Errorf("unexpected blacklist %+v", blacklist)
+	}
+	// defaults are not saved
+	if m.GetConfig().PaymentMethods != nil {
+		t.Errorf("default payment methods saved")
+	}
+}
+
+func TestAdvertiserRef(t *testing.T) {
+	seen := []offer.Offer{
+		{Venue: "binance", Advertiser: offer.Advertiser{ID: "b1", Nickname: "ref-alice"}},
+		{Venue: "binance", Advertiser: offer.Advertiser{ID: "b2", Nickname: "ref-bob"}},
+		{Venue: "okx", Advertiser: offer.Advertiser{ID: "o2", Nickname: "ref-bob"}},
+	}
+	for _, o := range seen {
+		rememberAdvertiser(o)
+		t.Cleanup(func() {
+			seenAdvertisers.Delete(seenAdvertiserKey{venue: o.Venue, nickname: o.Advertiser.Nickname})
+		})
+	}
+
+	tests := []struct {
+		nickname string
+		want     config.AdvertiserRef
+	}{
+		{nickname: "ref-alice", want: config.AdvertiserRef{Venue: "binance", UserNo: "b1", Nickname: "ref-alice"}},
+		// seen on several venues, the nickname is all they have in common
+		{nickname: "ref-bob", want: config.AdvertiserRef{Nickname: "ref-bob"}},
+		{nickname: "ref-carol", want: config.AdvertiserRef{Nickname: "ref-carol"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nickname, func(t *testing.T) {
+			if got := advertiserRef(tt.nickname); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSweepBlacklist(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	permanent := config.BlacklistEntry{AdvertiserRef: config.AdvertiserRef{Nickname: "alice"}}
+	expired := config.BlacklistEntry{AdvertiserRef: config.AdvertiserRef{Nickname: "bob"}, ExpiresAt: &past}
+	active := config.BlacklistEntry{AdvertiserRef: config.AdvertiserRef{Nickname: "carol"}, ExpiresAt: &future}
+
+	m := &memoryManager{cfg: config.Config{BlackList: config.Blacklist{permanent, expired, active}}}
+
+	got, err := sweepBlacklist(m, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != 1 || got[0].Nickname != "bob" {
+		t.Errorf("expected bob to expire, got %+v", got)
+	}
+
+	blacklist := m.GetConfig().BlackList
+	if len(blacklist) != 2 || blacklist[0].Nickname != "alice" || blacklist[1].Nickname != "carol" {
+		t.Errorf("unexpected blacklist %+v", blacklist)
+	}
+
+	// nothing else expired, the config is left untouched
+	saves := m.saves
+	got, err = sweepBlacklist(m, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != 0 || m.saves != saves {
+		t.Errorf("expected no expired entries and no save, got %+v and %d saves", got, m.saves-saves)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "reflect"
+
+// configCache : value built from a config section, built again only when the section changes, so that expensive
+// builds run, and their errors are reported, once
+type configCache[S, V any] struct {
+	built  bool
+	source S
+	value  V
+
+	build func(source S) V
+}
+
+// get : the value built from source
+func (c *configCache[S, V]) get(source S) V {
+	if !c.built || !reflect.DeepEqual(source, c.source) {
+		c.value = c.build(source)
+		c.source = source
+		c.built = true
+	}
+
+	return c.value
+}
+
+func newConfigCache[S, V any](build func(source S) V) *configCache[S, V] {
+	return &configCache[S, V]{build: build}
+}
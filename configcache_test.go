@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"p2p-check/src/config"
+)
+
+func TestConfigCache_Get(t *testing.T) {
+	builds := 0
+	c := newConfigCache(func(calendars []config.HolidayCalendar) int {
+		builds++
+		return len(calendars)
+	})
+
+	calendars := []config.HolidayCalendar{{Country: "JP", Dates: []string{"2024-12-30"}}}
+	c.get(calendars)
+	// equal, but not the same slice, as with every GetConfig
+	if got := c.get([]config.HolidayCalendar{{Country: "JP", Dates: []string{"2024-12-30"}}}); got != 1 || builds != 1 {
+		t.Errorf("expected a single build, got %d builds", builds)
+	}
+
+	calendars = append(calendars, config.HolidayCalendar{Country: "US"})
+	if got := c.get(calendars); got != 2 || builds != 2 {
+		t.Errorf("expected a new build after a change, got %d builds", builds)
+	}
+
+	// the zero value is built as well
+	c = newConfigCache(func([]config.HolidayCalendar) int { builds++; return 0 })
+	c.get(nil)
+	if builds != 3 {
+		t.Errorf("expected the first get to build, got %d builds", builds)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"p2p-check/src/config"
+	"p2p-check/src/offer"
+)
+
+// favoriteRemove : /favorite sub-command removing an advertiser from the favorites
+const favoriteRemove = "remove"
+
+func favoriteCallback(cfgManager config.Manager) func(args string) (string, error) {
+	return func(args string) (string, error) {
+		// favorite [remove] {name}
+		cleanArgs := strings.Fields(args)
+		if len(cleanArgs) == 0 {
+			return formatFavorites(cfgManager.GetConfig().Favorites), nil
+		}
+
+		var favorites config.Favorites
+		err := cfgManager.Update(func(c *config.Config) error {
+			switch {
+			case len(cleanArgs) == 1:
+				ref := advertiserRef(cleanArgs[0])
+				if c.Favorites.Contains(ref.Venue, ref.UserNo, ref.Nickname) {
+					return fmt.Errorf("'%s' is already a favorite", ref.Nickname)
+				}
+
+				c.Favorites = append(c.Favorites, config.Favorite{AdvertiserRef: ref, AddedAt: time.Now()})
+			case len(cleanArgs) == 2 && strings.ToLower(cleanArgs[0]) == favoriteRemove:
+				ref := advertiserRef(cleanArgs[1])
+				kept := make(config.Favorites, 0, len(c.Favorites))
+				for _, f := range c.Favorites {
+					if !f.Matches(ref.Venue, ref.UserNo, ref.Nickname) && f.Nickname != ref.Nickname {
+						kept = append(kept, f)
+					}
+				}
+				if len(kept) == len(c.Favorites) {
+					return fmt.Errorf("'%s' is not a favorite", ref.Nickname)
+				}
+
+				c.Favorites = kept
+			default:
+				return errors.New("invalid arguments")
+			}
+
+			favorites = c.Favorites
+
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return formatFavorites(favorites), nil
+	}
+}
+
+func formatFavorites(favorites config.Favorites) string {
+	if len(favorites) == 0 {
+		return "no favorites"
+	}
+
+	lines := make([]string, 0, len(favorites))
+	for _, f := range favorites {
+		lines = append(lines, ":star: "+formatAdvertiserRef(f.AdvertiserRef))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isFavorite(c config.Config, o offer.Offer) bool {
+	return c.Favorites.Contains(o.Venue, o.Advertiser.ID, o.Advertiser.Nickname)
+}
+
+// isBetterPrice : true if price is better than previous for side
+func isBetterPrice(side offer.Side, price, previous float64) bool {
+	if side == offer.SideSell {
+		return price > previous
+	}
+
+	return price < previous
+}
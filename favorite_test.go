@@ -0,0 +1,208 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"p2p-check/src/config"
+	"p2p-check/src/offer"
+)
+
+func TestIsGoodRate_Favorite(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		side        offer.Side
+		rateSurplus float64
+		favorite    bool
+		wantOk      bool
+	}{
+		{
+			name:        "buy above max surplus, not a favorite",
+			side:        offer.SideBuy,
+			rateSurplus: 1.3,
+		},
+		{
+			name:        "buy within the favorite extra",
+			side:        offer.SideBuy,
+			rateSurplus: 1.3,
+			favorite:    true,
+			wantOk:      true,
+		},
+		{
+			name:        "buy at the favorite threshold",
+			side:        offer.SideBuy,
+			rateSurplus: config.DefaultMaxSurplusPercentage + config.DefaultFavoriteExtraPercentage,
+			favorite:    true,
+			wantOk:      true,
+		},
+		{
+			name:        "buy above the favorite threshold",
+			side:        offer.SideBuy,
+			rateSurplus: 1.6,
+			favorite:    true,
+		},
+		{
+			name:        "sell below min premium, not a favorite",
+			side:        offer.SideSell,
+			rateSurplus: 0.7,
+		},
+		{
+			name:        "sell within the favorite extra",
+			side:        offer.SideSell,
+			rateSurplus: 0.7,
+			favorite:    true,
+			wantOk:      true,
+		},
+		{
+			name:        "sell below the favorite threshold",
+			side:        offer.SideSell,
+			rateSurplus: 0.4,
+			favorite:    true,
+		},
+		{
+			name:        "explicit zero favorite extra",
+			cfg:         config.Config{FavoriteExtraPercentage: config.Float64(0)},
+			side:        offer.SideBuy,
+			rateSurplus: 1.3,
+			favorite:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := isGoodRate(tt.cfg.WithDefaults(), tt.side, tt.rateSurplus, tt.favorite); ok != tt.wantOk {
+				t.Errorf("expected ok %t, got %t", tt.wantOk, ok)
+			}
+		})
+	}
+}
+
+func TestIsAdvertiserAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		side     offer.Side
+		notified bool
+		favorite bool
+		price    float64
+		want     bool
+	}{
+		{name: "never notified", side: offer.SideBuy, price: 150, want: true},
+		{name: "notified", side: offer.SideBuy, notified: true, price: 149},
+		{name: "favorite, same price", side: offer.SideBuy, notified: true, favorite: true, price: 150},
+		{name: "favorite, better buy price", side: offer.SideBuy, notified: true, favorite: true, price: 149, want: true},
+		{name: "favorite, worse buy price", side: offer.SideBuy, notified: true, favorite: true, price: 151},
+		{name: "favorite, better sell price", side: offer.SideSell, notified: true, favorite: true, price: 151, want: true},
+		{name: "favorite, worse sell price", side: offer.SideSell, notified: true, favorite: true, price: 149},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := offer.Offer{
+				Venue:      "binance",
+				Side:       tt.side,
+				Asset:      "USDT",
+				Advertiser: offer.Advertiser{ID: "allowed-u1", Nickname: "alice"},
+			}
+			if tt.notified {
+				spamFilter.Store(spamFilterKey(o), spamFilterEntry{notifiedAt: time.Now(), price: 150})
+				t.Cleanup(func() { spamFilter.Delete(spamFilterKey(o)) })
+			}
+
+			if got := isAdvertiserAllowed(o, tt.favorite, tt.price); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFavoritePrefix(t *testing.T) {
+	if got := favoritePrefix(true); got != ":star: " {
+		t.Errorf("expected a star for favorites, got '%s'", got)
+	}
+	if got := favoritePrefix(false); got != "" {
+		t.Errorf("expected no prefix, got '%s'", got)
+	}
+}
+
+func TestIsFavorite(t *testing.T) {
+	c := config.Config{Favorites: config.Favorites{
+		{AdvertiserRef: config.AdvertiserRef{Venue: "binance", UserNo: "u1", Nickname: "alice"}},
+	}}
+
+	tests := []struct {
+		name string
+		o    offer.Offer
+		want bool
+	}{
+		{
+			name: "renamed advertiser",
+			o:    offer.Offer{Venue: "binance", Advertiser: offer.Advertiser{ID: "u1", Nickname: "alice2"}},
+			want: true,
+		},
+		{
+			name: "same user number, other venue",
+			o:    offer.Offer{Venue: "okx", Advertiser: offer.Advertiser{ID: "u1", Nickname: "dave"}},
+		},
+		{
+			name: "same nickname, other venue",
+			o:    offer.Offer{Venue: "okx", Advertiser: offer.Advertiser{ID: "o9", Nickname: "alice"}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFavorite(c, tt.o); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFavoriteCallback(t *testing.T) {
+	m := &memoryManager{}
+	callback := favoriteCallback(m)
+
+	tests := []struct {
+		name          string
+		args          string
+		wantErr       bool
+		wantNicknames []string
+	}{
+		{name: "empty list", args: "", wantNicknames: nil},
+		{name: "add", args: "fav-alice", wantNicknames: []string{"fav-alice"}},
+		{name: "add another", args: "fav-bob", wantNicknames: []string{"fav-alice", "fav-bob"}},
+		{name: "list", args: "", wantNicknames: []string{"fav-alice", "fav-bob"}},
+		{name: "duplicate", args: "fav-alice", wantErr: true, wantNicknames: []string{"fav-alice", "fav-bob"}},
+		{name: "remove", args: "remove fav-alice", wantNicknames: []string{"fav-bob"}},
+		{name: "remove unknown", args: "remove fav-carol", wantErr: true, wantNicknames: []string{"fav-bob"}},
+		{name: "invalid arguments", args: "fav-alice fav-bob", wantErr: true, wantNicknames: []string{"fav-bob"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saves := m.saves
+			out, err := callback(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v", tt.wantErr, err)
+			}
+			if (tt.wantErr || tt.args == "") && m.saves != saves {
+				t.Errorf("expected no save")
+			}
+
+			favorites := m.GetConfig().Favorites
+			nicknames := make([]string, 0, len(favorites))
+			for _, f := range favorites {
+				nicknames = append(nicknames, f.Nickname)
+			}
+			if strings.Join(nicknames, ",") != strings.Join(tt.wantNicknames, ",") {
+				t.Errorf("expected favorites %v, got %v", tt.wantNicknames, nicknames)
+			}
+			if err == nil && len(tt.wantNicknames) > 0 && out != formatFavorites(favorites) {
+				t.Errorf("unexpected output '%s'", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"p2p-check/src/config"
+	"p2p-check/src/offer"
+)
+
+// remarkSnippetLength : amount of characters shown around a remark match
+const remarkSnippetLength = 40
+
+// isFillable : false for "bait" offers, that look good but cannot actually be traded because the amount left is below
+// the offer's own min amount, or below MinTradeAmount
+func isFillable(cfg config.Config, o offer.Offer) bool {
+	if !o.Tradable {
+		return false
+	}
+
+	tradableAmount := o.TradableAmount()
+	if tradableAmount <= 0 || tradableAmount < o.MinAmount {
+		return false
+	}
+
+	return cfg.MinTradeAmount <= 0 || tradableAmount >= cfg.MinTradeAmount
+}
+
+// isAdvertiserReliable : false if the advertiser of o does not meet AdvertiserFilters. Stats not provided by the venue
+// fail the filters setting a min for them, unless AllowUnknownStats is set
+func isAdvertiserReliable(cfg config.Config, o offer.Offer) bool {
+	f := cfg.AdvertiserFilters
+	a := o.Advertiser
+
+	if f.MerchantOnly && !a.IsMerchant {
+		return false
+	}
+
+	finishRate, orderCount := a.MonthFinishRate, float64(a.MonthOrderCount)
+	if f.AllTimeStatsFallback {
+		if finishRate < 0 {
+			finishRate = a.TotalFinishRate
+		}
+		if orderCount < 0 {
+			orderCount = float64(a.TotalOrderCount)
+		}
+	}
+
+	if !meetsMin(finishRate, f.MinFinishRate, f.AllowUnknownStats) ||
+		!meetsMin(orderCount, float64(f.MinMonthOrders), f.AllowUnknownStats) ||
+		!meetsMin(a.PositiveRate, f.MinPositiveRate, f.AllowUnknownStats) {
+		return false
+	}
+
+	if f.MaxInactiveMinutes > 0 && !a.LastActiveAt.IsZero() &&
+		time.Since(a.LastActiveAt) > time.Duration(f.MaxInactiveMinutes)*time.Minute {
+		return false
+	}
+
+	return true
+}
+
+// meetsMin : true if stat is at least min. Unknown (negative) stats only pass if min is not set, or if allowUnknown
+func meetsMin(stat, min float64, allowUnknown bool) bool {
+	if stat < 0 {
+		return min <= 0 || allowUnknown
+	}
+
+	return stat >= min
+}
+
+// remarkFilters : compiled config.RemarkFilters
+type remarkFilters struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// compileRemarkFilters : keywords are matched literally. Invalid regular expressions are ignored, and returned in errs
+func compileRemarkFilters(f config.RemarkFilters) (filters remarkFilters, errs []error) {
+	var includeErrs, excludeErrs []error
+	filters.include, includeErrs = compileRemarkPatterns(f.Include, f.IncludeRegex)
+	filters.exclude, excludeErrs = compileRemarkPatterns(f.Exclude, f.ExcludeRegex)
+
+	return filters, append(includeErrs, excludeErrs...)
+}
+
+func compileRemarkPatterns(keywords, expressions []string) ([]*regexp.Regexp, []error) {
+	res := make([]*regexp.Regexp, 0, len(keywords)+len(expressions))
+	var errs []error
+
+	for _, k := range keywords {
+		res = append(res, regexp.MustCompile("(?i)"+regexp.QuoteMeta(k)))
+	}
+
+	for _, e := range expressions {
+		re, err := regexp.Compile("(?i)" + e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid remark filter '%s': %w", e, err))
+			continue
+		}
+
+		res = append(res, re)
+	}
+
+	return res, errs
+}
+
+// matchRemarks : false if remarks or auto-reply message of o match exclusions, or don't match any inclusion. snippet
+// is the text around the matched inclusion, if any
+func matchRemarks(filters remarkFilters, o offer.Offer) (snippet string, ok bool) {
+	text := o.Remarks + "\n" + o.AutoReplyMessage
+
+	for _, re := range filters.exclude {
+		if re.MatchString(text) {
+			return "", false
+		}
+	}
+
+	if len(filters.include) == 0 {
+		return "", true
+	}
+
+	for _, re := range filters.include {
+		if loc := re.FindStringIndex(text); loc != nil {
+			return remarkSnippet(text, loc[0], loc[1]), true
+		}
+	}
+
+	return "", false
+}
+
+// remarkSnippet : text[start:end] with up to remarkSnippetLength characters around it
+func remarkSnippet(text string, start, end int) string {
+	before := []rune(text[:start])
+	after := []rune(text[end:])
+
+	prefix, suffix := "", ""
+	if len(before) > remarkSnippetLength {
+		before = before[len(before)-remarkSnippetLength:]
+		prefix = "..."
+	}
+	if len(after) > remarkSnippetLength {
+		after = after[:remarkSnippetLength]
+		suffix = "..."
+	}
+
+	return prefix + string(before) + text[start:end] + string(after) + suffix
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"p2p-check/src/client/binance"
+	"p2p-check/src/config"
+	"p2p-check/src/offer"
+)
+
+func TestIsFillable(t *testing.T) {
+	// 1000 USDT left at 150, so up to 150000 JPY
+	base := offer.Offer{Price: 150, AvailableAmount: 1000, MinAmount: 10000, Tradable: true}
+
+	// the advertiser balance only allows trading 8000 JPY, below the min amount of the ad
+	var d binance.P2PData
+	d.Adv.Price = 150
+	d.Adv.SurplusAmount = 1000
+	d.Adv.MinSingleTransAmount = 10000
+	d.Adv.MaxSingleTransAmount = 150000
+	d.Adv.DynamicMaxSingleTransAmount = 8000
+	d.Adv.IsTradable = true
+	dynamicMaxCapped := d.ToOffer()
+
+	tests := []struct {
+		name           string
+		o              func(o offer.Offer) offer.Offer
+		minTradeAmount float64
+		want           bool
+	}{
+		{name: "fillable", o: func(o offer.Offer) offer.Offer { return o }, want: true},
+		{name: "not tradable", o: func(o offer.Offer) offer.Offer { o.Tradable = false; return o }, want: false},
+		{
+			name: "remaining below own min amount",
+			o:    func(o offer.Offer) offer.Offer { o.AvailableAmount = 50; return o },
+			want: false,
+		},
+		{
+			name: "max amount below own min amount",
+			o:    func(o offer.Offer) offer.Offer { o.MaxAmount = 5000; return o },
+			want: false,
+		},
+		{name: "nothing left", o: func(o offer.Offer) offer.Offer { o.AvailableAmount = 0; return o }, want: false},
+		{
+			name:           "remaining below MinTradeAmount",
+			o:              func(o offer.Offer) offer.Offer { o.AvailableAmount = 100; return o },
+			minTradeAmount: 20000,
+			want:           false,
+		},
+		{
+			name:           "remaining above MinTradeAmount",
+			o:              func(o offer.Offer) offer.Offer { return o },
+			minTradeAmount: 20000,
+			want:           true,
+		},
+		{name: "dynamic max cap", o: func(offer.Offer) offer.Offer { return dynamicMaxCapped }, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{MinTradeAmount: tt.minTradeAmount}
+
+			if got := isFillable(cfg, tt.o(base)); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsAdvertiserReliable(t *testing.T) {
+	filters := config.AdvertiserFilters{
+		MinFinishRate:      0.95,
+		MinPositiveRate:    0.98,
+		MinMonthOrders:     50,
+		MaxInactiveMinutes: 30,
+	}
+	reliable := offer.Advertiser{
+		MonthOrderCount: 100,
+		MonthFinishRate: 0.99,
+		PositiveRate:    0.99,
+		TotalOrderCount: -1,
+		TotalFinishRate: -1,
+		LastActiveAt:    time.Now().Add(-time.Minute),
+	}
+	// like OKX advertisers: all-time stats only
+	unknownStats := func(a offer.Advertiser) offer.Advertiser {
+		a.MonthOrderCount, a.MonthFinishRate, a.PositiveRate, a.LastActiveAt = -1, -1, -1, time.Time{}
+		a.TotalOrderCount, a.TotalFinishRate = 500, 0.98
+		return a
+	}
+
+	tests := []struct {
+		name       string
+		advertiser func(a offer.Advertiser) offer.Advertiser
+		filters    func(f config.AdvertiserFilters) config.AdvertiserFilters
+		want       bool
+	}{
+		{name: "reliable", want: true},
+		{
+			name:       "low finish rate",
+			advertiser: func(a offer.Advertiser) offer.Advertiser { a.MonthFinishRate = 0.9; return a },
+			want:       false,
+		},
+		{
+			name:       "few orders",
+			advertiser: func(a offer.Advertiser) offer.Advertiser { a.MonthOrderCount = 10; return a },
+			want:       false,
+		},
+		{
+			name:       "low positive rate",
+			advertiser: func(a offer.Advertiser) offer.Advertiser { a.PositiveRate = 0.9; return a },
+			want:       false,
+		},
+		{
+			name:       "inactive",
+			advertiser: func(a offer.Advertiser) offer.Advertiser { a.LastActiveAt = time.Now().Add(-time.Hour); return a },
+			want:       false,
+		},
+		{
+			name:       "unknown stats",
+			advertiser: unknownStats,
+			want:       false,
+		},
+		{
+			name:       "unknown stats allowed",
+			advertiser: unknownStats,
+			filters:    func(f config.AdvertiserFilters) config.AdvertiserFilters { f.AllowUnknownStats = true; return f },
+			want:       true,
+		},
+		{
+			name:       "unknown stats without min",
+			advertiser: unknownStats,
+			filters: func(f config.AdvertiserFilters) config.AdvertiserFilters {
+				f.MinFinishRate, f.MinMonthOrders, f.MinPositiveRate = 0, 0, 0
+				return f
+			},
+			want: true,
+		},
+		{
+			// the positive rate is still unknown
+			name:       "all-time stats fallback",
+			advertiser: unknownStats,
+			filters: func(f config.AdvertiserFilters) config.AdvertiserFilters {
+				f.AllTimeStatsFallback = true
+				return f
+			},
+			want: false,
+		},
+		{
+			name:       "all-time stats fallback without positive rate",
+			advertiser: unknownStats,
+			filters: func(f config.AdvertiserFilters) config.AdvertiserFilters {
+				f.AllTimeStatsFallback, f.MinPositiveRate = true, 0
+				return f
+			},
+			want: true,
+		},
+		{
+			name: "all-time stats fallback with low finish rate",
+			advertiser: func(a offer.Advertiser) offer.Advertiser {
+				a = unknownStats(a)
+				a.TotalFinishRate = 0.9
+				return a
+			},
+			filters: func(f config.AdvertiserFilters) config.AdvertiserFilters {
+				f.AllTimeStatsFallback, f.MinPositiveRate = true, 0
+				return f
+			},
+			want: false,
+		},
+		{
+			// 30 days stats are used when provided
+			name: "all-time stats fallback with known stats",
+			advertiser: func(a offer.Advertiser) offer.Advertiser {
+				a.TotalOrderCount, a.TotalFinishRate = 1000, 0.5
+				return a
+			},
+			filters: func(f config.AdvertiserFilters) config.AdvertiserFilters { f.AllTimeStatsFallback = true; return f },
+			want:    true,
+		},
+		{
+			name:    "merchant only",
+			filters: func(f config.AdvertiserFilters) config.AdvertiserFilters { f.MerchantOnly = true; return f },
+			want:    false,
+		},
+		{
+			name: "no filters",
+			advertiser: func(a offer.Advertiser) offer.Advertiser {
+				a.MonthOrderCount, a.MonthFinishRate, a.PositiveRate = 0, 0, 0
+				return a
+			},
+			filters: func(config.AdvertiserFilters) config.AdvertiserFilters { return config.AdvertiserFilters{} },
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, f := reliable, filters
+			if tt.advertiser != nil {
+				a = tt.advertiser(a)
+			}
+			if tt.filters != nil {
+				f = tt.filters(f)
+			}
+
+			cfg := config.Config{AdvertiserFilters: f}
+			if got := isAdvertiserReliable(cfg, offer.Offer{Advertiser: a}); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchRemarks(t *testing.T) {
+	tests := []struct {
+		name        string
+		filters     config.RemarkFilters
+		remarks     string
+		autoReply   string
+		wantOK      bool
+		wantSnippet string
+	}{
+		{name: "no filters", remarks: "anything", wantOK: true},
+		{
+			name:        "case-insensitive keyword",
+			filters:     config.RemarkFilters{Include: []string{"fast RELEASE"}},
+			remarks:     "Fast release guaranteed",
+			wantOK:      true,
+			wantSnippet: "Fast release guaranteed\n",
+		},
+		{
+			name:        "non-ASCII keyword",
+			filters:     config.RemarkFilters{Include: []string{"銀行振込"}},
+			autoReply:   "銀行振込のみ",
+			wantOK:      true,
+			wantSnippet: "\n銀行振込のみ",
+		},
+		{
+			name:    "non-ASCII case folding",
+			filters: config.RemarkFilters{Exclude: []string{"ÉTÉ"}},
+			remarks: "fermé en été",
+			wantOK:  false,
+		},
+		{
+			name:    "keywords are literal",
+			filters: config.RemarkFilters{Include: []string{"a.b"}},
+			remarks: "axb",
+			wantOK:  false,
+		},
+		{
+			name:    "regex",
+			filters: config.RemarkFilters{ExcludeRegex: []string{"only (for )?regulars?"}},
+			remarks: "Only for regular customers",
+			wantOK:  false,
+		},
+		{
+			name:    "exclude wins over include",
+			filters: config.RemarkFilters{Include: []string{"bank"}, Exclude: []string{"no third party"}},
+			remarks: "Bank transfer, no third party payments",
+			wantOK:  false,
+		},
+		{
+			name:    "include not matched",
+			filters: config.RemarkFilters{Include: []string{"paypay"}},
+			remarks: "Bank transfer only",
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, errs := compileRemarkFilters(tt.filters)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors %v", errs)
+			}
+
+			snippet, ok := matchRemarks(filters, offer.Offer{Remarks: tt.remarks, AutoReplyMessage: tt.autoReply})
+			if ok != tt.wantOK || snippet != tt.wantSnippet {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tt.wantSnippet, tt.wantOK, snippet, ok)
+			}
+		})
+	}
+}
+
+func TestCompileRemarkFilters_Invalid(t *testing.T) {
+	filters, errs := compileRemarkFilters(config.RemarkFilters{
+		Include:      []string{"(literal"},
+		ExcludeRegex: []string{"(unclosed", "valid"},
+	})
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if len(filters.include) != 1 || len(filters.exclude) != 1 {
+		t.Errorf("expected invalid expressions to be skipped, got %+v", filters)
+	}
+}
+
+func TestRemarkSnippet(t *testing.T) {
+	// 50 multi-byte runes on each side of the match
+	before := strings.Repeat("あ", 50)
+	after := strings.Repeat("い", 50)
+	text := before + "USDT" + after
+
+	snippet := remarkSnippet(text, len(before), len(before)+len("USDT"))
+
+	want := "..." + strings.Repeat("あ", remarkSnippetLength) + "USDT" + strings.Repeat("い", remarkSnippetLength) + "..."
+	if snippet != want {
+		t.Errorf("expected %q, got %q", want, snippet)
+	}
+	if !utf8.ValidString(snippet) {
+		t.Error("snippet cut in the middle of a rune")
+	}
+
+	// short texts are not truncated
+	if snippet = remarkSnippet("é USDT é", 3, 7); snippet != "é USDT é" {
+		t.Errorf("unexpected snippet %q", snippet)
+	}
+}
@@ -5,11 +5,11 @@ go 1.20
 require (
 	github.com/pkg/errors v0.9.1
 	github.com/slack-go/slack v0.12.2
+	go.uber.org/atomic v1.7.0
 	go.uber.org/zap v1.24.0
 )
 
 require (
 	github.com/gorilla/websocket v1.4.2 // indirect
-	go.uber.org/atomic v1.7.0 // indirect
 	go.uber.org/multierr v1.6.0 // indirect
 )
@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -16,12 +15,20 @@ import (
 
 	"go.uber.org/atomic"
 
+	"p2p-check/src/backoff"
 	"p2p-check/src/client/binance"
+	"p2p-check/src/client/httpclient"
+	"p2p-check/src/client/okx"
 	"p2p-check/src/config"
 	"p2p-check/src/event"
 	"p2p-check/src/forex"
+	"p2p-check/src/holiday"
 	"p2p-check/src/logger"
+	"p2p-check/src/marketplace"
 	"p2p-check/src/notification"
+	"p2p-check/src/offer"
+	"p2p-check/src/price"
+	"p2p-check/src/schedule"
 )
 
 const (
@@ -30,10 +37,20 @@ const (
 
 	// MinCheckTime : fx data will be fetched no more than once every MinCheckTime
 	MinCheckTime = time.Minute
+
+	// HTTPTimeout : max amount of time a single HTTP call to a P2P venue or to a price provider can take
+	HTTPTimeout = 15 * time.Second
 )
 
+// marketplaceBackoff : how long to stop polling a P2P venue after it starts throttling us
+var marketplaceBackoff = backoff.Backoff{
+	Base:   2 * time.Minute,
+	Max:    time.Hour,
+	Jitter: 0.2,
+}
+
 var (
-	// spamFilter : avoids re-sending notifications about the same advertiser
+	// spamFilter : avoids re-sending notifications about the same advertiser. Values are spamFilterEntry
 	spamFilter = sync.Map{}
 	// if paused, fx data won't be fetched
 	paused = atomic.NewBool(false)
@@ -75,16 +92,31 @@ func main() {
 		panic(err)
 	}
 
-	fx := forex.NewFastForexClient(os.Getenv("FASTFOREX_API_KEY"), http.DefaultClient)
-	bnClient := binance.NewClient()
 	notificationClient := notification.NewSlack(slackNotificationWebhookURL)
+	startupCfg := cfgManager.GetConfig().WithDefaults()
+	fx, err := newForexClient(startupCfg, notificationClient, &http.Client{Timeout: HTTPTimeout})
+	if err != nil {
+		panic(err)
+	}
+	fx = forex.NewCache(fx,
+		time.Duration(startupCfg.ForexCacheTTLSeconds)*time.Second,
+		time.Duration(startupCfg.ForexMaxStalenessMinutes)*time.Minute,
+	)
+	p2pHTTPClient := &http.Client{Timeout: HTTPTimeout}
+	priceClient := price.NewBinanceSpot(price.BinanceSpotHost, p2pHTTPClient)
+	referenceClient := price.NewKraken(price.KrakenHost, p2pHTTPClient)
+	marketplaces := []marketplace.Client{
+		marketplace.NewBinance(binance.NewClient(binance.P2PHost, p2pHTTPClient)),
+		marketplace.NewOKX(okx.NewClient(okx.Host, p2pHTTPClient)),
+	}
 	eventClient := event.NewSlack(slackAppToken).
 		WithCallback(event.Blacklist, blackListCallback(cfgManager)).
+		WithCallback(event.Favorite, favoriteCallback(cfgManager)).
 		WithCallback(event.Pause, pauseCallback()).
 		WithCallback(event.Restart, restartCallback())
 
 	errChan := make(chan error)
-	resChan := make(chan float64)
+	resChan := make(chan forex.Quote)
 
 	_, err = eventClient.Listen(mainContext)
 	if err != nil {
@@ -93,59 +125,61 @@ func main() {
 
 	go startSpamFilterLoop(mainContext)
 
+	go startBlacklistSweepLoop(mainContext, cfgManager, notificationClient, errChan)
+
 	go startErrLoop(errChan)
 
 	go startForexLoop(mainContext, fx, errChan, resChan, cfgManager)
 
-	go startAdvLoop(mainContext, bnClient, notificationClient, cfgManager, errChan, resChan)
+	go startAdvLoop(mainContext, marketplaces, priceClient, referenceClient, notificationClient, cfgManager, errChan, resChan)
 
 	<-mainContext.Done()
 	close(errChan)
 	close(resChan)
 }
 
-func blackListCallback(cfgManager config.Manager) func(args string) (string, error) {
-	return func(args string) (string, error) {
-		generateAnswerAndSaveConfig := func(c config.Config) string {
-			answer := fmt.Sprintf("Line: %s\nBank:%s",
-				strings.Join(c.BlackList.Line, ","),
-				strings.Join(c.BlackList.Bank, ","),
-			)
-
-			cfgManager.SaveConfig(c)
-
-			return answer
+// newForexClient : combines the ForexProviders according to ForexStrategy. cfg must have its defaults set
+func newForexClient(
+	cfg config.Config, notificationClient notification.Client, httpClient httpclient.Client,
+) (forex.Client, error) {
+	providers := make([]forex.Client, 0, len(cfg.ForexProviders))
+	for _, p := range cfg.ForexProviders {
+		apiKey := p.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv(strings.ToUpper(p.Name) + "_API_KEY")
 		}
 
-		c := cfgManager.GetConfig()
-
-		// blacklist {name} {method}
-		if args == "" {
-			return generateAnswerAndSaveConfig(c), nil
+		provider, err := forex.New(p.Name, forex.ProviderOptions{
+			APIKey:     apiKey,
+			BaseURL:    p.BaseURL,
+			HTTPClient: httpClient,
+		})
+		if err != nil {
+			return nil, err
 		}
 
-		argsSplit := strings.Split(args, " ")
-		cleanArgs := make([]string, 0, len(argsSplit))
-		for _, a := range argsSplit {
-			if newArg := strings.TrimSpace(a); newArg != "" {
-				cleanArgs = append(cleanArgs, newArg)
+		providers = append(providers, provider)
+	}
+
+	switch cfg.ForexStrategy {
+	case config.ForexStrategyAggregate:
+		return forex.NewAggregator(*cfg.ForexMaxDeviationPercentage, providers...), nil
+	case config.ForexStrategyFailover:
+		onSwitch := func(previous, current string, err error) {
+			msg := fmt.Sprintf("FX rates now come from '%s' instead of '%s'", current, previous)
+			if err != nil {
+				msg += fmt.Sprintf(", that failed: %s", err)
 			}
-		}
 
-		if len(cleanArgs) < 2 {
-			return "", errors.New("invalid arguments")
+			if err = notificationClient.SendMessage(msg); err != nil {
+				logger.Default.Error("cannot notify fx provider switch: " + err.Error())
+			}
 		}
 
-		switch method := strings.ToLower(cleanArgs[1]); method {
-		case "line":
-			c.BlackList.Line = append(c.BlackList.Line, cleanArgs[0])
-			return generateAnswerAndSaveConfig(c), nil
-		case "bank":
-			c.BlackList.Bank = append(c.BlackList.Bank, cleanArgs[0])
-			return generateAnswerAndSaveConfig(c), nil
-		default:
-			return "", errors.New(fmt.Sprintf("method '%s' not supported", method))
-		}
+		cooldown := time.Duration(cfg.ForexFailoverCooldownMinutes) * time.Minute
+		return forex.NewFailover(cooldown, onSwitch, providers...), nil
+	default:
+		return nil, fmt.Errorf("forex strategy '%s' not supported", cfg.ForexStrategy)
 	}
 }
 
@@ -179,7 +213,7 @@ func startSpamFilterLoop(ctx context.Context) {
 			return
 		case <-ticker.C:
 			spamFilter.Range(func(key, value any) bool {
-				if value.(int64) < time.Now().Add(-MinSpamWaitTime).Unix() {
+				if value.(spamFilterEntry).notifiedAt.Before(time.Now().Add(-MinSpamWaitTime)) {
 					spamFilter.Delete(key)
 				}
 
@@ -197,7 +231,7 @@ func startErrLoop(errChan chan error) {
 
 func startForexLoop(
 	ctx context.Context,
-	fc forex.Client, errChan chan error, resultChan chan float64,
+	fc forex.Client, errChan chan error, resultChan chan forex.Quote,
 	cfgManager config.Manager,
 ) {
 	tickerTime := time.Duration(math.Max(float64(MinCheckTime), float64(fc.GetMaxRate())))
@@ -209,13 +243,13 @@ func startForexLoop(
 		}
 
 		logger.Default.Info("fetching new rate")
-		currentRate, err := fc.GetCurrentFxRate(ctx, "USD", cfgManager.GetConfig().TargetCurrency)
+		quote, err := forex.GetQuote(ctx, fc, "USD", cfgManager.GetConfig().WithDefaults().TargetCurrency)
 		if err != nil {
 			errChan <- err
 			return
 		}
-		logger.Default.WithField("rate", currentRate).Info("new rate fetched")
-		resultChan <- currentRate
+		logger.Default.WithField("rate", quote.Rate).WithField("sources", quote.Sources).Info("new rate fetched")
+		resultChan <- quote
 	}
 
 	cycle()
@@ -232,69 +266,70 @@ func startForexLoop(
 
 func startAdvLoop(
 	ctx context.Context,
-	bc *binance.Client, notificationClient notification.Client, cfgManager config.Manager,
-	errChan chan error, rateChan chan float64,
+	marketplaces []marketplace.Client, priceClient price.Client, referenceClient price.ReferenceClient,
+	notificationClient notification.Client, cfgManager config.Manager, errChan chan error, rateChan chan forex.Quote,
 ) {
-	var newAdvs []binance.P2PData
-	var newRate float64
-	var err error
+	var newRate forex.Quote
+
+	breakers := make(map[string]*backoff.Breaker, len(marketplaces))
+	for _, mc := range marketplaces {
+		breakers[mc.Name()] = backoff.NewBreaker(marketplaceBackoff)
+	}
+
+	calendars := newConfigCache(func(c []config.HolidayCalendar) holiday.Registry {
+		return loadHolidayCalendars(c, errChan)
+	})
+	schedules := newConfigCache(func(s scheduleSource) paymentSchedules {
+		compiled, errs := compilePaymentSchedules(s.methods, s.holidays)
+		for _, err := range errs {
+			errChan <- err
+		}
+
+		return compiled
+	})
+	remarks := newConfigCache(func(f config.RemarkFilters) remarkFilters {
+		filters, errs := compileRemarkFilters(f)
+		for _, err := range errs {
+			errChan <- err
+		}
+
+		return filters
+	})
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case newRate = <-rateChan:
-			logger.Default.Info("fetching advs")
-			// TODO export as variables
-			newAdvs, err = bc.ListP2PAdvs("USDT", cfgManager.GetConfig().TargetCurrency)
-			if err != nil {
-				errChan <- err
+			cfg := cfgManager.GetConfig().WithDefaults()
+
+			maxStaleness := time.Duration(cfg.ForexMaxStalenessMinutes) * time.Minute
+			if age := newRate.Age(); age > maxStaleness {
+				errChan <- fmt.Errorf("fx rate is %s old, not checking ads", age.Round(time.Second))
 				continue
 			}
 
-			logger.Default.WithField("advs-size", len(newAdvs)).Info("ads fetched")
+			holidays := calendars.get(cfg.HolidayCalendars)
+			paySchedules := schedules.get(scheduleSource{methods: cfg.PaymentMethods, holidays: holidays})
+			compiledRemarks := remarks.get(cfg.RemarkFilters)
 
-			for _, adv := range newAdvs {
-				advRate, advErr := strconv.ParseFloat(adv.Adv.Price, 64)
-				if advErr != nil {
-					errChan <- advErr
+			for _, asset := range cfg.Assets {
+				rate, err := getMarketRate(ctx, priceClient, referenceClient, asset, newRate, cfg.UsePegReference)
+				if err != nil {
+					errChan <- err
 					continue
 				}
 
-				rateSurplus := (advRate/newRate)*100 - 100
-
-				if rateSurplus <= cfgManager.GetConfig().MaxSurplusPercentage {
-					if !isAdvertiserAllowed(cfgManager, adv.Advertiser.NickName) {
+				for _, mc := range marketplaces {
+					if !isVenueEnabled(cfg, mc.Name()) {
 						continue
 					}
 
-					// good offer
-					methods := make([]string, 0, len(adv.Adv.TradeMethods))
-					for _, method := range adv.Adv.TradeMethods {
-						if isPayMethodAllowed(method.Identifier) {
-							methods = append(methods, method.TradeMethodName)
-						}
-					}
-
-					if len(methods) > 0 {
-						msg := fmt.Sprintf("advertiser '%s' has a good offer."+
-							"\n\tFX rate: %f"+
-							"\n\tOffer rate: %f"+
-							"\n\tDistance: %f"+
-							"\n\tAmount: %s"+
-							"\n\tMethods: %s\n",
-							adv.Advertiser.NickName,
-							newRate,
-							advRate,
-							rateSurplus,
-							adv.Adv.SurplusAmount,
-							strings.Join(methods, ","))
-						if err = notificationClient.SendMessage(msg); err != nil {
-							errChan <- advErr
-							continue
-						}
-
-						spamFilter.Store(adv.Advertiser.NickName, time.Now().Unix())
+					for _, tradeType := range cfg.TradeTypes {
+						checkAdvs(
+							ctx, mc, breakers[mc.Name()], notificationClient, cfg, paySchedules, compiledRemarks,
+							asset, offer.Side(tradeType), rate, errChan,
+						)
 					}
 				}
 			}
@@ -302,49 +337,552 @@ func startAdvLoop(
 	}
 }
 
-func isPayMethodAllowed(method string) bool {
-	switch method {
-	case "LINEPay":
-		return true
-	case "BANK":
-		// only allowed from Monday to friday from 6AM to 14:30 PM UTC+9
-		location, _ := time.LoadLocation("Asia/Tokyo") // UTC+9 corresponds to Asia/Tokyo timezone
-		t := time.Now().In(location)
-
-		hour := t.Hour()
-		minute := t.Minute()
-
-		// check if it's Monday to Friday
-		if t.Weekday() >= time.Monday && t.Weekday() <= time.Friday {
-			// check if it's between 6AM and 14:30 PM
-			if hour > 6 && (hour < 14 || (hour == 14 && minute <= 30)) {
-				return true
+// marketRate : reference rates P2P offers are compared with
+type marketRate struct {
+	// fx : USD -> fiat rate
+	fx float64
+	// fxSources : providers fx comes from
+	fxSources []string
+	// fxFetchedAt : when fx was fetched
+	fxFetchedAt time.Time
+	// assetPrice : price of 1 unit of the asset, in pegAsset. 1 for stablecoins
+	assetPrice float64
+	// pegAsset : stablecoin assetPrice is expressed in
+	pegAsset string
+	// peg : USD price of 1 unit of pegAsset. 1 unless the peg reference is enabled
+	peg float64
+	// pegReference : true if peg comes from a price.ReferenceClient
+	pegReference bool
+}
+
+// fairPrice : fiat amount 1 unit of the asset is worth
+func (r marketRate) fairPrice() float64 {
+	return r.fx * r.assetPrice * r.peg
+}
+
+// getMarketRate : stablecoins are considered worth 1 unit of themselves, the other assets are priced with
+// priceClient. If usePeg is set, the stablecoin is then priced in USD with referenceClient, otherwise 1:1 is assumed.
+// 1:1 is assumed as well for stablecoins referenceClient cannot price at all.
+func getMarketRate(
+	ctx context.Context, priceClient price.Client, referenceClient price.ReferenceClient,
+	asset string, fxQuote forex.Quote, usePeg bool,
+) (marketRate, error) {
+	rate := marketRate{
+		fx:          fxQuote.Rate,
+		fxSources:   fxQuote.Sources,
+		fxFetchedAt: fxQuote.FetchedAt,
+		assetPrice:  1,
+		pegAsset:    strings.ToUpper(asset),
+		peg:         1,
+	}
+
+	if !price.IsStablecoin(asset) {
+		assetPrice, err := priceClient.GetUSDPrice(ctx, asset)
+		if err != nil {
+			return marketRate{}, fmt.Errorf("cannot get %s price: %w", asset, err)
+		}
+		rate.assetPrice = assetPrice
+		rate.pegAsset = priceClient.QuoteAsset()
+	}
+
+	if usePeg && price.IsStablecoin(rate.pegAsset) {
+		peg, err := referenceClient.GetPeg(ctx, rate.pegAsset)
+		switch {
+		case errors.Is(err, price.ErrUnknownPeg):
+			// the stablecoin would otherwise be skipped at every check
+			logger.Default.WithField("asset", rate.pegAsset).WithError(err).Warn("peg unknown, assuming 1:1")
+		case err != nil:
+			return marketRate{}, fmt.Errorf("cannot get %s peg: %w", rate.pegAsset, err)
+		default:
+			rate.peg = peg
+			rate.pegReference = true
+		}
+	}
+
+	return rate, nil
+}
+
+// checkAdvs : fetch ads for asset and side from mc and notify the ones whose price is good enough compared to the fair
+// price in rate (see isGoodRate). If FillAmount is set, the effective price of filling it is checked as well.
+// While breaker is open, mc is not called at all.
+func checkAdvs(
+	ctx context.Context,
+	mc marketplace.Client, breaker *backoff.Breaker, notificationClient notification.Client,
+	cfg config.Config, schedules paymentSchedules, remarks remarkFilters,
+	asset string, side offer.Side, rate marketRate, errChan chan error,
+) {
+	if !breaker.Allow() {
+		return
+	}
+
+	logger.Default.WithField("venue", mc.Name()).WithField("asset", asset).WithField("side", side).Info("fetching advs")
+	offers, err := mc.ListOffers(ctx, marketplace.Query{
+		Asset:           asset,
+		Fiat:            cfg.TargetCurrency,
+		Side:            side,
+		PaymentMethods:  cfg.PayTypes,
+		TransAmount:     cfg.TransAmount,
+		MerchantOnly:    cfg.PublisherType == "merchant",
+		MaxPages:        cfg.MaxPages,
+		MaxOffers:       cfg.MaxAds,
+		Countries:       cfg.Countries,
+		ProMerchantOnly: cfg.ProMerchantAds,
+	})
+	if err != nil {
+		if httpclient.IsThrottled(err) {
+			handleThrottling(mc.Name(), breaker, notificationClient, err, errChan)
+			return
+		}
+
+		errChan <- err
+		return
+	}
+
+	if breaker.Success() {
+		if err = notificationClient.SendMessage(fmt.Sprintf("%s is reachable again: ad polling restarted", mc.Name())); err != nil {
+			errChan <- err
+		}
+	}
+
+	logger.Default.WithField("venue", mc.Name()).WithField("advs-size", len(offers)).Info("ads fetched")
+
+	fairPrice := rate.fairPrice()
+
+	// offers we could trade on, regardless of their price
+	tradable := make([]offer.Offer, 0, len(offers))
+
+	for _, o := range offers {
+		if !isFillable(cfg, o) {
+			continue
+		}
+
+		rememberAdvertiser(o)
+
+		if !isAdvertiserReliable(cfg, o) {
+			continue
+		}
+
+		remarkSnippet, ok := matchRemarks(remarks, o)
+		if !ok {
+			continue
+		}
+
+		methods := allowedMethods(cfg, schedules, o)
+		if len(methods) == 0 {
+			continue
+		}
+
+		tradable = append(tradable, o)
+
+		fiatAmount := tradeAmount(cfg, o)
+		method, effectivePrice := bestMethod(cfg, o, methods, fiatAmount)
+		rateSurplus := (effectivePrice/fairPrice)*100 - 100
+
+		favorite := isFavorite(cfg, o)
+
+		distanceLabel, ok := isGoodRate(cfg, side, rateSurplus, favorite)
+		if !ok {
+			continue
+		}
+
+		if !isAdvertiserAllowed(o, favorite, effectivePrice) {
+			continue
+		}
+
+		// good offer
+		msg := fmt.Sprintf("%s[%s][%s %s] %s '%s' has a good offer."+
+			"\n\tFX rate: %f (%s)"+
+			"%s"+
+			"\n\t%s price: %f %s"+
+			"%s"+
+			"\n\tFair price: %f"+
+			"\n\tOffer rate: %f"+
+			"\n\tEffective rate: %f (fees included, trading %f %s with %s)"+
+			"\n\t%s: %f"+
+			"\n\tAmount: %f"+
+			"\n\tMethods: %s"+
+			"%s\n",
+			favoritePrefix(favorite),
+			o.Venue,
+			side,
+			asset,
+			advertiserLabel(favorite),
+			o.Advertiser.Nickname,
+			rate.fx,
+			strings.Join(rate.fxSources, ","),
+			fxAgeLine(rate),
+			asset,
+			rate.assetPrice,
+			rate.pegAsset,
+			pegLine(rate),
+			fairPrice,
+			o.Price,
+			effectivePrice,
+			fiatAmount,
+			cfg.TargetCurrency,
+			method.Name,
+			distanceLabel,
+			math.Abs(rateSurplus),
+			o.AvailableAmount,
+			strings.Join(methodNames(methods), ","),
+			remarksLine(remarkSnippet))
+		if err = notificationClient.SendMessage(msg); err != nil {
+			errChan <- err
+			continue
+		}
+
+		spamFilter.Store(spamFilterKey(o), spamFilterEntry{notifiedAt: time.Now(), price: effectivePrice})
+	}
+
+	if cfg.FillAmount > 0 {
+		checkFill(notificationClient, cfg, schedules, mc.Name(), asset, side, rate, tradable, errChan)
+	}
+}
+
+// checkFill : notify if the volume-weighted price of trading FillAmount across offers is good enough, listing the
+// offers to trade on
+func checkFill(
+	notificationClient notification.Client, cfg config.Config, schedules paymentSchedules,
+	venue, asset string, side offer.Side, rate marketRate, offers []offer.Offer, errChan chan error,
+) {
+	plan := offer.Fill(offers, side, cfg.FillAmount)
+	if !plan.Complete {
+		logger.Default.
+			WithField("venue", venue).
+			WithField("fillable", plan.FiatAmount).
+			Info("not enough liquidity to fill the target amount")
+		return
+	}
+
+	// fees are paid for each leg
+	var totalFiat, totalAsset float64
+	for _, leg := range plan.Legs {
+		method, _ := bestMethod(cfg, leg.Offer, allowedMethods(cfg, schedules, leg.Offer), leg.FiatAmount)
+		fiat, asset := leg.Offer.Trade(leg.FiatAmount, method, paymentMethodFee(cfg, method.Identifier))
+		totalFiat += fiat
+		totalAsset += asset
+	}
+
+	fairPrice := rate.fairPrice()
+	effectivePrice := totalFiat / totalAsset
+	rateSurplus := (effectivePrice/fairPrice)*100 - 100
+
+	distanceLabel, ok := isGoodRate(cfg, side, rateSurplus, false)
+	if !ok {
+		return
+	}
+
+	key := fillSpamFilterKey(venue, side, asset)
+	if _, ok = spamFilter.Load(key); ok {
+		return
+	}
+
+	legs := make([]string, 0, len(plan.Legs))
+	for _, leg := range plan.Legs {
+		legs = append(legs, fmt.Sprintf("\n\t\t'%s' at %f: %f %s (%f %s)",
+			leg.Offer.Advertiser.Nickname,
+			leg.Offer.Price,
+			leg.FiatAmount,
+			cfg.TargetCurrency,
+			leg.AssetAmount,
+			asset))
+	}
+
+	msg := fmt.Sprintf("[%s][%s %s] %f %s can be filled at a good effective price."+
+		"\n\tFX rate: %f (%s)"+
+		"%s"+
+		"%s"+
+		"\n\tFair price: %f"+
+		"\n\tEffective rate: %f (fees included)"+
+		"\n\t%s: %f"+
+		"\n\tOffers:%s\n",
+		venue,
+		side,
+		asset,
+		cfg.FillAmount,
+		cfg.TargetCurrency,
+		rate.fx,
+		strings.Join(rate.fxSources, ","),
+		fxAgeLine(rate),
+		pegLine(rate),
+		fairPrice,
+		effectivePrice,
+		distanceLabel,
+		math.Abs(rateSurplus),
+		strings.Join(legs, ""))
+	if err := notificationClient.SendMessage(msg); err != nil {
+		errChan <- err
+		return
+	}
+
+	spamFilter.Store(key, spamFilterEntry{notifiedAt: time.Now(), price: effectivePrice})
+}
+
+// isGoodRate : for BUY, a rate is good when it is at most MaxSurplusPercentage above the fair price; for SELL, when it
+// is at least MinPremiumPercentage above it. Thresholds are loosened by FavoriteExtraPercentage for favorites. label
+// describes rateSurplus in notifications
+func isGoodRate(cfg config.Config, side offer.Side, rateSurplus float64, favorite bool) (label string, ok bool) {
+	var extra float64
+	if favorite {
+		extra = *cfg.FavoriteExtraPercentage
+	}
+
+	switch side {
+	case offer.SideSell:
+		return "Premium above FX", rateSurplus >= *cfg.MinPremiumPercentage-extra
+	default:
+		return "Distance below FX", rateSurplus <= *cfg.MaxSurplusPercentage+extra
+	}
+}
+
+// allowedMethods : payment methods of o that can currently be used, and that the advertiser is not blacklisted for
+func allowedMethods(cfg config.Config, schedules paymentSchedules, o offer.Offer) []offer.PaymentMethod {
+	methods := make([]offer.PaymentMethod, 0, len(o.PaymentMethods))
+	for _, method := range o.PaymentMethods {
+		if isPayMethodAllowed(schedules, method.Identifier) && !isAdvertiserBlacklisted(cfg, o, method.Identifier) {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
+
+func methodNames(methods []offer.PaymentMethod) []string {
+	names := make([]string, 0, len(methods))
+	for _, method := range methods {
+		names = append(names, method.Name)
+	}
+
+	return names
+}
+
+// tradeAmount : fiat amount we would trade on o. TransAmount if set (within o limits), the max tradable otherwise
+func tradeAmount(cfg config.Config, o offer.Offer) float64 {
+	amount := o.TradableAmount()
+	if cfg.TransAmount > 0 {
+		amount = math.Max(math.Min(cfg.TransAmount, amount), o.MinAmount)
+	}
+
+	return amount
+}
+
+// bestMethod : among methods, the one giving the best effective price when trading fiatAmount on o, fees and
+// commission included. methods must not be empty
+func bestMethod(
+	cfg config.Config, o offer.Offer, methods []offer.PaymentMethod, fiatAmount float64,
+) (offer.PaymentMethod, float64) {
+	var best offer.PaymentMethod
+	var bestPrice float64
+
+	for i, method := range methods {
+		p := o.EffectivePrice(fiatAmount, method, paymentMethodFee(cfg, method.Identifier))
+
+		isBetter := p < bestPrice
+		if o.Side == offer.SideSell {
+			isBetter = p > bestPrice
+		}
+
+		if i == 0 || isBetter {
+			best, bestPrice = method, p
+		}
+	}
+
+	return best, bestPrice
+}
+
+func paymentMethodFee(cfg config.Config, identifier string) offer.Fee {
+	for _, f := range cfg.PaymentMethodFees {
+		if f.Identifier == identifier {
+			return offer.Fee{
+				Fixed:      f.Fixed,
+				Percentage: f.Percentage,
 			}
 		}
 	}
 
+	return offer.Fee{}
+}
+
+// favoritePrefix : highlights notifications about favorites
+func favoritePrefix(favorite bool) string {
+	if favorite {
+		return ":star: "
+	}
+
+	return ""
+}
+
+func advertiserLabel(favorite bool) string {
+	if favorite {
+		return "favorite advertiser"
+	}
+
+	return "advertiser"
+}
+
+// remarksLine : notification line showing the matched remarks, if any
+func remarksLine(snippet string) string {
+	if snippet == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\tRemarks: %s", strings.ReplaceAll(snippet, "\n", " "))
+}
+
+// fxAgeLine : warns about fx rates served from the cache for at least a minute
+func fxAgeLine(rate marketRate) string {
+	age := time.Since(rate.fxFetchedAt)
+	if age < time.Minute {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\tFX rate %d minutes old", int(age.Minutes()))
+}
+
+// pegLine : notification line showing the peg used to compute the fair price, if any
+func pegLine(rate marketRate) string {
+	if !rate.pegReference {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\tPeg (%s/USD): %f", rate.pegAsset, rate.peg)
+}
+
+// handleThrottling : open breaker, honoring Retry-After if any. Only the first failure of a sequence is notified,
+// the following ones are just logged
+func handleThrottling(
+	venue string, breaker *backoff.Breaker, notificationClient notification.Client, err error, errChan chan error,
+) {
+	var retryAfter time.Duration
+	var statusErr *httpclient.StatusError
+	if errors.As(err, &statusErr) {
+		retryAfter = statusErr.RetryAfter
+	}
+
+	opened, until := breaker.Failure(retryAfter)
+
+	logger.Default.WithField("venue", venue).WithField("until", until).Warn("ad polling paused: " + err.Error())
+
+	if opened {
+		msg := fmt.Sprintf("%s is throttling us (%s): ad polling paused until %s",
+			venue, err, until.Format(time.RFC3339))
+		if err = notificationClient.SendMessage(msg); err != nil {
+			errChan <- err
+		}
+	}
+}
+
+// spamFilterEntry : when a notification was sent, and the effective price it was about
+type spamFilterEntry struct {
+	notifiedAt time.Time
+	price      float64
+}
+
+// spamFilterKey : an advertiser can have both BUY and SELL ads for several assets, possibly on several venues, so
+// they are filtered separately. Advertisers are identified by ID, since nicknames can change
+func spamFilterKey(o offer.Offer) string {
+	advertiser := o.Advertiser.ID
+	if advertiser == "" {
+		advertiser = o.Advertiser.Nickname
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s", o.Venue, o.Side, o.Asset, advertiser)
+}
+
+// fillSpamFilterKey : fill notifications are filtered separately from the ones about single advertisers
+func fillSpamFilterKey(venue string, side offer.Side, asset string) string {
+	return fmt.Sprintf("fill/%s/%s/%s", venue, side, asset)
+}
+
+func isVenueEnabled(c config.Config, venue string) bool {
+	for _, v := range c.Venues {
+		if strings.EqualFold(v, venue) {
+			return true
+		}
+	}
+
 	return false
 }
 
-func isAdvertiserAllowed(configManager config.Manager, advertiserNickname string) bool {
-	// spam filter
-	if _, ok := spamFilter.Load(advertiserNickname); ok {
+// paymentSchedules : compiled schedules of the configured PaymentMethods, by identifier. nil schedules mean always
+type paymentSchedules map[string]*schedule.Compiled
+
+// scheduleSource : what paymentSchedules are compiled from
+type scheduleSource struct {
+	methods  []config.PaymentMethod
+	holidays holiday.Registry
+}
+
+// compilePaymentSchedules : schedules of methods, looking up their holidays in holidays. Methods with an invalid
+// schedule are left out, so that they are not accepted, and reported
+func compilePaymentSchedules(methods []config.PaymentMethod, holidays holiday.Registry) (paymentSchedules, []error) {
+	schedules := make(paymentSchedules, len(methods))
+	var errs []error
+
+	for _, m := range methods {
+		if _, ok := schedules[m.Identifier]; ok {
+			continue
+		}
+
+		if m.Schedule == nil {
+			schedules[m.Identifier] = nil
+			continue
+		}
+
+		compiled, err := m.Schedule.Compile(holidays)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s schedule: %w", m.Identifier, err))
+			continue
+		}
+		schedules[m.Identifier] = compiled
+	}
+
+	return schedules, errs
+}
+
+// isPayMethodAllowed : true if method is in the configured PaymentMethods and its schedule, if any, is open now
+func isPayMethodAllowed(schedules paymentSchedules, method string) bool {
+	s, ok := schedules[method]
+	if !ok {
 		return false
 	}
 
-	// blacklist filter
-	// TODO ban only for the correct payment method
-	c := configManager.GetConfig()
-	for _, blackListedUser := range c.BlackList.Line {
-		if advertiserNickname == blackListedUser {
-			return false
+	return s == nil || s.IsOpen(time.Now())
+}
+
+// loadHolidayCalendars : built-in calendars plus the configured ones. Invalid ones are reported and
+// skipped
+func loadHolidayCalendars(calendars []config.HolidayCalendar, errChan chan error) holiday.Registry {
+	holidays := holiday.NewRegistry()
+
+	for _, hc := range calendars {
+		dates, err := holiday.ParseDates(hc.Dates)
+		if err != nil {
+			errChan <- fmt.Errorf("invalid %s holidays: %w", hc.Country, err)
+		} else {
+			holidays.Add(hc.Country, dates)
 		}
-	}
-	for _, blackListedUser := range c.BlackList.Bank {
-		if advertiserNickname == blackListedUser {
-			return false
+
+		for _, file := range hc.Files {
+			if dates, err = holiday.LoadFile(file); err != nil {
+				errChan <- fmt.Errorf("invalid %s holidays: %w", hc.Country, err)
+				continue
+			}
+
+			holidays.Add(hc.Country, dates)
 		}
 	}
 
-	return true
+	return holidays
+}
+
+// isAdvertiserAllowed : false if we recently notified about the advertiser of o, unless they are a favorite and
+// their price improved since then
+func isAdvertiserAllowed(o offer.Offer, favorite bool, effectivePrice float64) bool {
+	value, ok := spamFilter.Load(spamFilterKey(o))
+	if !ok {
+		return true
+	}
+
+	return favorite && isBetterPrice(o.Side, effectivePrice, value.(spamFilterEntry).price)
 }
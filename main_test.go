@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"p2p-check/src/config"
+	"p2p-check/src/forex"
+	"p2p-check/src/holiday"
+	"p2p-check/src/logger"
+	"p2p-check/src/offer"
+	"p2p-check/src/price"
+	"p2p-check/src/schedule"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+
+	os.Exit(m.Run())
+}
+
+// stubPriceClient : price.Client returning prices by asset, in quote
+type stubPriceClient struct {
+	prices map[string]float64
+	quote  string
+}
+
+func (s stubPriceClient) GetUSDPrice(_ context.Context, asset string) (float64, error) {
+	p, ok := s.prices[asset]
+	if !ok {
+		return 0, errors.New("unknown asset")
+	}
+
+	return p, nil
+}
+
+func (s stubPriceClient) QuoteAsset() string {
+	return s.quote
+}
+
+// stubReferenceClient : price.ReferenceClient returning pegs by stablecoin, or err if set
+type stubReferenceClient struct {
+	pegs map[string]float64
+	err  error
+}
+
+func (s stubReferenceClient) GetPeg(_ context.Context, stablecoin string) (float64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	p, ok := s.pegs[stablecoin]
+	if !ok {
+		return 0, price.ErrUnknownPeg
+	}
+
+	return p, nil
+}
+
+func TestIsGoodRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		side        offer.Side
+		rateSurplus float64
+		wantLabel   string
+		wantOk      bool
+	}{
+		{
+			name:        "buy below max surplus",
+			side:        offer.SideBuy,
+			rateSurplus: 0.5,
+			wantLabel:   "Distance below FX",
+			wantOk:      true,
+		},
+		{
+			name:        "buy at max surplus",
+			side:        offer.SideBuy,
+			rateSurplus: config.DefaultMaxSurplusPercentage,
+			wantLabel:   "Distance below FX",
+			wantOk:      true,
+		},
+		{
+			name:        "buy above max surplus",
+			side:        offer.SideBuy,
+			rateSurplus: 1.5,
+			wantLabel:   "Distance below FX",
+		},
+		{
+			name:        "buy with explicit zero max surplus, at fair price",
+			cfg:         config.Config{MaxSurplusPercentage: config.Float64(0)},
+			side:        offer.SideBuy,
+			rateSurplus: 0,
+			wantLabel:   "Distance below FX",
+			wantOk:      true,
+		},
+		{
+			name:        "buy with explicit zero max surplus, above fair price",
+			cfg:         config.Config{MaxSurplusPercentage: config.Float64(0)},
+			side:        offer.SideBuy,
+			rateSurplus: 0.1,
+			wantLabel:   "Distance below FX",
+		},
+		{
+			name:        "sell above min premium",
+			side:        offer.SideSell,
+			rateSurplus: 1.5,
+			wantLabel:   "Premium above FX",
+			wantOk:      true,
+		},
+		{
+			name:        "sell at min premium",
+			side:        offer.SideSell,
+			rateSurplus: config.DefaultMinPremiumPercentage,
+			wantLabel:   "Premium above FX",
+			wantOk:      true,
+		},
+		{
+			name:        "sell below min premium",
+			side:        offer.SideSell,
+			rateSurplus: 0.5,
+			wantLabel:   "Premium above FX",
+		},
+		{
+			// a price below the fair one is never good when selling, the comparison is flipped
+			name:        "sell below fair price",
+			side:        offer.SideSell,
+			rateSurplus: -2,
+			wantLabel:   "Premium above FX",
+		},
+		{
+			name:        "sell with explicit zero min premium, at fair price",
+			cfg:         config.Config{MinPremiumPercentage: config.Float64(0)},
+			side:        offer.SideSell,
+			rateSurplus: 0,
+			wantLabel:   "Premium above FX",
+			wantOk:      true,
+		},
+		{
+			name:        "sell with explicit zero min premium, below fair price",
+			cfg:         config.Config{MinPremiumPercentage: config.Float64(0)},
+			side:        offer.SideSell,
+			rateSurplus: -0.1,
+			wantLabel:   "Premium above FX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, ok := isGoodRate(tt.cfg.WithDefaults(), tt.side, tt.rateSurplus, false)
+			if ok != tt.wantOk {
+				t.Errorf("expected ok %t, got %t", tt.wantOk, ok)
+			}
+			if label != tt.wantLabel {
+				t.Errorf("expected label '%s', got '%s'", tt.wantLabel, label)
+			}
+		})
+	}
+}
+
+func TestGetMarketRate(t *testing.T) {
+	priceClient := stubPriceClient{prices: map[string]float64{"BTC": 30000}, quote: "USDT"}
+	fxQuote := forex.Quote{Rate: 150, Sources: []string{"fastforex"}, FetchedAt: time.Now()}
+
+	tests := []struct {
+		name           string
+		asset          string
+		wantFairPrice  float64
+		wantAssetPrice float64
+		wantPegAsset   string
+		wantErr        bool
+	}{
+		{name: "stablecoin", asset: "usdc", wantFairPrice: 150, wantAssetPrice: 1, wantPegAsset: "USDC"},
+		{name: "priced asset", asset: "BTC", wantFairPrice: 4500000, wantAssetPrice: 30000, wantPegAsset: "USDT"},
+		{name: "price not available", asset: "ETH", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := getMarketRate(context.Background(), priceClient, nil, tt.asset, fxQuote, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", rate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if rate.fairPrice() != tt.wantFairPrice {
+				t.Errorf("expected fair price %f, got %f", tt.wantFairPrice, rate.fairPrice())
+			}
+			if rate.assetPrice != tt.wantAssetPrice || rate.pegAsset != tt.wantPegAsset {
+				t.Errorf("unexpected asset price %f %s", rate.assetPrice, rate.pegAsset)
+			}
+			// without peg reference, stablecoins are worth 1 USD
+			if rate.peg != 1 || rate.pegReference {
+				t.Errorf("unexpected peg %+v", rate)
+			}
+			if rate.fx != fxQuote.Rate || len(rate.fxSources) != 1 || !rate.fxFetchedAt.Equal(fxQuote.FetchedAt) {
+				t.Errorf("unexpected fx %+v", rate)
+			}
+		})
+	}
+}
+
+func TestGetMarketRate_Peg(t *testing.T) {
+	priceClient := stubPriceClient{prices: map[string]float64{"BTC": 30000}, quote: "USDT"}
+	fxQuote := forex.Quote{Rate: 150, FetchedAt: time.Now()}
+
+	tests := []struct {
+		name             string
+		asset            string
+		referenceClient  stubReferenceClient
+		wantFairPrice    float64
+		wantPegReference bool
+		wantErr          bool
+	}{
+		{
+			name:             "depegged stablecoin",
+			asset:            "USDT",
+			referenceClient:  stubReferenceClient{pegs: map[string]float64{"USDT": 0.98}},
+			wantFairPrice:    147,
+			wantPegReference: true,
+		},
+		{
+			name:             "asset priced in a depegged stablecoin",
+			asset:            "BTC",
+			referenceClient:  stubReferenceClient{pegs: map[string]float64{"USDT": 0.98}},
+			wantFairPrice:    4410000,
+			wantPegReference: true,
+		},
+		{
+			name:            "stablecoin without peg reference",
+			asset:           "FDUSD",
+			referenceClient: stubReferenceClient{pegs: map[string]float64{"USDT": 0.98}},
+			wantFairPrice:   150,
+		},
+		{
+			name:            "peg lookup failing",
+			asset:           "USDT",
+			referenceClient: stubReferenceClient{err: errors.New("unavailable")},
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := getMarketRate(context.Background(), priceClient, tt.referenceClient, tt.asset, fxQuote, true)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", rate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if math.Abs(rate.fairPrice()-tt.wantFairPrice) > 1e-6 {
+				t.Errorf("expected fair price %f, got %f", tt.wantFairPrice, rate.fairPrice())
+			}
+			if rate.pegReference != tt.wantPegReference {
+				t.Errorf("expected peg reference %t, got %t", tt.wantPegReference, rate.pegReference)
+			}
+		})
+	}
+}
+
+func TestBestMethod(t *testing.T) {
+	bank := offer.PaymentMethod{Identifier: "BANK", Name: "Bank Transfer"}
+	line := offer.PaymentMethod{Identifier: "LINEPay", Name: "LINE Pay"}
+	cfg := config.Config{PaymentMethodFees: []config.PaymentMethodFee{
+		{Identifier: "BANK", Fixed: 440},
+		{Identifier: "LINEPay", Percentage: 1},
+	}}.WithDefaults()
+
+	tests := []struct {
+		name       string
+		o          offer.Offer
+		methods    []offer.PaymentMethod
+		fiatAmount float64
+		wantMethod string
+		wantPrice  float64
+	}{
+		{
+			// 440 on 20000 is 2.2%, more than the 1% of LINE Pay
+			name:       "buy, small amount",
+			o:          offer.Offer{Side: offer.SideBuy, Price: 148.65},
+			methods:    []offer.PaymentMethod{bank, line},
+			fiatAmount: 20000,
+			wantMethod: "LINEPay",
+			wantPrice:  148.65 * 1.01,
+		},
+		{
+			// 440 on 1000000 is 0.044%
+			name:       "buy, large amount",
+			o:          offer.Offer{Side: offer.SideBuy, Price: 148.65},
+			methods:    []offer.PaymentMethod{bank, line},
+			fiatAmount: 1000000,
+			wantMethod: "BANK",
+			wantPrice:  148.65 * 1.00044,
+		},
+		{
+			name:       "sell, small amount",
+			o:          offer.Offer{Side: offer.SideSell, Price: 151.35},
+			methods:    []offer.PaymentMethod{bank, line},
+			fiatAmount: 20000,
+			wantMethod: "LINEPay",
+			wantPrice:  151.35 * 0.99,
+		},
+		{
+			name:       "sell, large amount",
+			o:          offer.Offer{Side: offer.SideSell, Price: 151.35},
+			methods:    []offer.PaymentMethod{line, bank},
+			fiatAmount: 1000000,
+			wantMethod: "BANK",
+			wantPrice:  151.35 * 0.99956,
+		},
+		{
+			// the commission of the method overrides the one of the offer
+			name: "method commission",
+			o:    offer.Offer{Side: offer.SideBuy, Price: 150, CommissionRate: 0.002},
+			methods: []offer.PaymentMethod{
+				{Identifier: "PayPay", CommissionRate: 0.001},
+				{Identifier: "Wise"},
+			},
+			fiatAmount: 20000,
+			wantMethod: "PayPay",
+			wantPrice:  150 / 0.999,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, p := bestMethod(cfg, tt.o, tt.methods, tt.fiatAmount)
+			if method.Identifier != tt.wantMethod {
+				t.Errorf("expected method %s, got %s", tt.wantMethod, method.Identifier)
+			}
+			if math.Abs(p-tt.wantPrice) > 1e-6 {
+				t.Errorf("expected price %f, got %f", tt.wantPrice, p)
+			}
+		})
+	}
+}
+
+func TestBestMethod_FeeMakesDealBad(t *testing.T) {
+	// an ad 0.9% below a fair price of 150, paid by bank transfer
+	fairPrice := 150.0
+	o := offer.Offer{Side: offer.SideBuy, Price: fairPrice * 0.991}
+	methods := []offer.PaymentMethod{{Identifier: "BANK", Name: "Bank Transfer"}}
+
+	noFee := config.Config{}.WithDefaults()
+	withFee := config.Config{PaymentMethodFees: []config.PaymentMethodFee{{Identifier: "BANK", Fixed: 440}}}.WithDefaults()
+
+	for _, tt := range []struct {
+		name       string
+		cfg        config.Config
+		fiatAmount float64
+		wantOk     bool
+	}{
+		{name: "no fee", cfg: noFee, fiatAmount: 20000, wantOk: true},
+		{name: "fee on a small amount", cfg: withFee, fiatAmount: 20000, wantOk: false},
+		{name: "fee on a large amount", cfg: withFee, fiatAmount: 1000000, wantOk: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, effectivePrice := bestMethod(tt.cfg, o, methods, tt.fiatAmount)
+			rateSurplus := (effectivePrice/fairPrice)*100 - 100
+
+			if _, ok := isGoodRate(tt.cfg, o.Side, rateSurplus, false); ok != tt.wantOk {
+				t.Errorf("expected good rate %t, got %t (%f%% from fair price)", tt.wantOk, ok, rateSurplus)
+			}
+		})
+	}
+}
+
+func TestPaymentMethodFee(t *testing.T) {
+	cfg := config.Config{PaymentMethodFees: []config.PaymentMethodFee{
+		{Identifier: "BANK", Fixed: 440},
+		{Identifier: "LINEPay", Percentage: 1},
+	}}
+
+	if fee := paymentMethodFee(cfg, "BANK"); fee.Fixed != 440 || fee.Percentage != 0 {
+		t.Errorf("unexpected BANK fee %+v", fee)
+	}
+	if fee := paymentMethodFee(cfg, "LINEPay"); fee.Fixed != 0 || fee.Percentage != 1 {
+		t.Errorf("unexpected LINEPay fee %+v", fee)
+	}
+	// identifiers are matched exactly, methods without a configured fee are free
+	if fee := paymentMethodFee(cfg, "bank"); fee != (offer.Fee{}) {
+		t.Errorf("expected no fee, got %+v", fee)
+	}
+}
+
+func TestCompilePaymentSchedules(t *testing.T) {
+	holidays := holiday.NewRegistry()
+	methods := []config.PaymentMethod{
+		{Identifier: "LINEPay"},
+		{Identifier: "BANK", Schedule: &schedule.Schedule{Timezone: "Asia/Tokyo", Holidays: "JP"}},
+		{Identifier: "PayPay", Schedule: &schedule.Schedule{Timezone: "Mars/Olympus"}},
+		{Identifier: "Wise", Schedule: &schedule.Schedule{Holidays: "XX"}},
+		{Identifier: "Revolut", Schedule: &schedule.Schedule{Weekdays: []string{"funday"}}},
+	}
+
+	schedules, errs := compilePaymentSchedules(methods, holidays)
+	if len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+
+	for method, want := range map[string]bool{
+		"LINEPay": true,
+		"PayPay":  false,
+		"Wise":    false,
+		"Revolut": false,
+		"Unknown": false,
+	} {
+		if got := isPayMethodAllowed(schedules, method); got != want {
+			t.Errorf("expected %s allowed %t, got %t", method, want, got)
+		}
+	}
+	if _, ok := schedules["BANK"]; !ok {
+		t.Errorf("expected a BANK schedule")
+	}
+}
+
+func TestCompilePaymentSchedules_Cached(t *testing.T) {
+	errChan := make(chan error, 10)
+	holidays := loadHolidayCalendars(nil, errChan)
+
+	builds := 0
+	schedules := newConfigCache(func(s scheduleSource) paymentSchedules {
+		builds++
+		compiled, _ := compilePaymentSchedules(s.methods, s.holidays)
+		return compiled
+	})
+
+	// the default payment methods are new slices at every check
+	for i := 0; i < 3; i++ {
+		cfg := config.Config{}.WithDefaults()
+		schedules.get(scheduleSource{methods: cfg.PaymentMethods, holidays: holidays})
+	}
+	if builds != 1 {
+		t.Errorf("expected a single build, got %d", builds)
+	}
+}
@@ -0,0 +1,36 @@
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff : exponential backoff with jitter
+type Backoff struct {
+	// Base : duration of the first wait
+	Base time.Duration
+	// Max : waits never exceed this duration
+	Max time.Duration
+	// Jitter : ratio [0, 1] of the duration that is randomized, to avoid retrying in lockstep
+	Jitter float64
+}
+
+// Duration : how long to wait before the attempt-th retry (starting from 1)
+func (b Backoff) Duration(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := float64(b.Base) * math.Pow(2, float64(attempt-1))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+
+	if b.Jitter > 0 {
+		// remove up to Jitter*d, so that Max is still honored
+		d -= d * b.Jitter * rand.Float64()
+	}
+
+	return time.Duration(d)
+}
@@ -0,0 +1,46 @@
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"p2p-check/src/backoff"
+)
+
+func TestBackoff_Duration(t *testing.T) {
+	b := backoff.Backoff{Base: time.Minute, Max: 10 * time.Minute}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Minute},
+		{attempt: 1, want: time.Minute},
+		{attempt: 2, want: 2 * time.Minute},
+		{attempt: 3, want: 4 * time.Minute},
+		{attempt: 4, want: 8 * time.Minute},
+		{attempt: 5, want: 10 * time.Minute},
+		{attempt: 100, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := b.Duration(tt.attempt); got != tt.want {
+			t.Errorf("attempt %d: expected %s, got %s", tt.attempt, tt.want, got)
+		}
+	}
+}
+
+func TestBackoff_Duration_Jitter(t *testing.T) {
+	b := backoff.Backoff{Base: time.Minute, Max: 10 * time.Minute, Jitter: 0.2}
+
+	for attempt := 1; attempt <= 6; attempt++ {
+		full := backoff.Backoff{Base: b.Base, Max: b.Max}.Duration(attempt)
+		lowest := time.Duration(float64(full) * (1 - b.Jitter))
+
+		for i := 0; i < 100; i++ {
+			if got := b.Duration(attempt); got < lowest || got > full {
+				t.Fatalf("attempt %d: %s out of [%s, %s]", attempt, got, lowest, full)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// Breaker : circuit breaker that stays open for an exponentially increasing amount of time after each consecutive
+// failure, so that a throttling upstream is not hammered
+type Breaker struct {
+	lock *sync.Mutex
+
+	backoff   Backoff
+	failures  int
+	openUntil time.Time
+}
+
+// Allow : false while the breaker is open
+func (b *Breaker) Allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return !time.Now().Before(b.openUntil)
+}
+
+// Failure : open the breaker for the next backoff duration, or retryAfter if longer.
+// opened is true only for the first failure of a sequence, so that callers can alert just once.
+func (b *Breaker) Failure(retryAfter time.Duration) (opened bool, until time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures++
+
+	wait := b.backoff.Duration(b.failures)
+	if retryAfter > wait {
+		wait = retryAfter
+	}
+	b.openUntil = time.Now().Add(wait)
+
+	return b.failures == 1, b.openUntil
+}
+
+// Success : close the breaker. recovered is true if there were failures before
+func (b *Breaker) Success() (recovered bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	recovered = b.failures > 0
+	b.failures = 0
+	b.openUntil = time.Time{}
+
+	return recovered
+}
+
+func NewBreaker(backoff Backoff) *Breaker {
+	return &Breaker{
+		lock:    &sync.Mutex{},
+		backoff: backoff,
+	}
+}
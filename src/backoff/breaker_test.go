@@ -0,0 +1,74 @@
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"p2p-check/src/backoff"
+)
+
+func TestBreaker_Failure(t *testing.T) {
+	b := backoff.NewBreaker(backoff.Backoff{Base: time.Minute, Max: time.Hour})
+
+	if !b.Allow() {
+		t.Fatal("expected a new breaker to be closed")
+	}
+
+	start := time.Now()
+	opened, until := b.Failure(0)
+	if !opened {
+		t.Error("expected the first failure to open the breaker")
+	}
+	if until.Before(start.Add(time.Minute)) || until.After(time.Now().Add(time.Minute)) {
+		t.Errorf("expected the breaker to be open for a minute, until %s", until)
+	}
+	if b.Allow() {
+		t.Error("expected the breaker to be open")
+	}
+
+	// following failures don't report opening again, and wait longer
+	opened, until = b.Failure(0)
+	if opened {
+		t.Error("expected only the first failure to report opening")
+	}
+	if until.Before(start.Add(2 * time.Minute)) {
+		t.Errorf("expected the breaker to be open for 2 minutes, until %s", until)
+	}
+}
+
+func TestBreaker_Failure_RetryAfter(t *testing.T) {
+	b := backoff.NewBreaker(backoff.Backoff{Base: time.Minute, Max: time.Hour})
+
+	start := time.Now()
+	if _, until := b.Failure(30 * time.Minute); until.Before(start.Add(30 * time.Minute)) {
+		t.Errorf("expected retryAfter to win over a shorter backoff, until %s", until)
+	}
+
+	// shorter than the backoff, ignored
+	b = backoff.NewBreaker(backoff.Backoff{Base: time.Hour, Max: time.Hour})
+	if _, until := b.Failure(time.Second); until.Before(start.Add(time.Hour)) {
+		t.Errorf("expected the backoff to win over a shorter retryAfter, until %s", until)
+	}
+}
+
+func TestBreaker_Success(t *testing.T) {
+	b := backoff.NewBreaker(backoff.Backoff{Base: time.Minute, Max: time.Hour})
+
+	if b.Success() {
+		t.Error("expected no recovery without failures")
+	}
+
+	b.Failure(0)
+	b.Failure(0)
+	if !b.Success() {
+		t.Error("expected recovery after failures")
+	}
+	if !b.Allow() {
+		t.Error("expected the breaker to be closed after a success")
+	}
+
+	// a new sequence of failures alerts again
+	if opened, _ := b.Failure(0); !opened {
+		t.Error("expected the first failure after a recovery to open the breaker")
+	}
+}
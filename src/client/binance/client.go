@@ -2,117 +2,137 @@ package binance
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"p2p-check/src/client/httpclient"
 	"p2p-check/src/logger"
 )
 
 const (
 	P2PHost = "https://p2p.binance.com"
+
+	// MaxRowsPerPage : max amount of ads Binance returns for a single page
+	MaxRowsPerPage = 20
+	// PageDelay : time to wait between two consecutive page requests, to avoid hammering the API
+	PageDelay = 500 * time.Millisecond
 )
 
+type P2PTradeMethod struct {
+	PayID                int64  `json:"payId"`
+	PayMethodID          string `json:"payMethodId"`
+	PayType              string `json:"payType"`
+	PayAccount           string `json:"payAccount"`
+	PayBank              string `json:"payBank"`
+	PaySubBank           string `json:"paySubBank"`
+	Identifier           string `json:"identifier"`
+	IconURLColor         string `json:"iconUrlColor"`
+	TradeMethodName      string `json:"tradeMethodName"`
+	TradeMethodShortName string `json:"tradeMethodShortName"`
+	TradeMethodBgColor   string `json:"tradeMethodBgColor"`
+}
+
+type P2PTradeMethodCommissionRate struct {
+	TradeMethodIdentifier string `json:"tradeMethodIdentifier"`
+	CommissionRate        Amount `json:"commissionRate"`
+}
+
+type P2PAdv struct {
+	AdvNo                           string                         `json:"advNo"`
+	Classify                        Classify                       `json:"classify"`
+	TradeType                       TradeType                      `json:"tradeType"`
+	Asset                           string                         `json:"asset"`
+	FiatUnit                        string                         `json:"fiatUnit"`
+	AdvStatus                       int                            `json:"advStatus"`
+	PriceType                       PriceType                      `json:"priceType"`
+	PriceFloatingRatio              Amount                         `json:"priceFloatingRatio"`
+	RateFloatingRatio               Amount                         `json:"rateFloatingRatio"`
+	CurrencyRate                    Amount                         `json:"currencyRate"`
+	Price                           Amount                         `json:"price"`
+	InitAmount                      Amount                         `json:"initAmount"`
+	SurplusAmount                   Amount                         `json:"surplusAmount"`
+	AmountAfterEditing              Amount                         `json:"amountAfterEditing"`
+	MaxSingleTransAmount            Amount                         `json:"maxSingleTransAmount"`
+	MinSingleTransAmount            Amount                         `json:"minSingleTransAmount"`
+	BuyerKycLimit                   int                            `json:"buyerKycLimit"`
+	BuyerRegDaysLimit               int                            `json:"buyerRegDaysLimit"`
+	BuyerBtcPositionLimit           Amount                         `json:"buyerBtcPositionLimit"`
+	Remarks                         string                         `json:"remarks"`
+	AutoReplyMsg                    string                         `json:"autoReplyMsg"`
+	PayTimeLimit                    int                            `json:"payTimeLimit"`
+	TradeMethods                    []P2PTradeMethod               `json:"tradeMethods"`
+	UserTradeCountFilterTime        int                            `json:"userTradeCountFilterTime"`
+	UserBuyTradeCountMin            int                            `json:"userBuyTradeCountMin"`
+	UserBuyTradeCountMax            int                            `json:"userBuyTradeCountMax"`
+	UserSellTradeCountMin           int                            `json:"userSellTradeCountMin"`
+	UserSellTradeCountMax           int                            `json:"userSellTradeCountMax"`
+	UserAllTradeCountMin            int                            `json:"userAllTradeCountMin"`
+	UserAllTradeCountMax            int                            `json:"userAllTradeCountMax"`
+	UserTradeCompleteRateFilterTime int                            `json:"userTradeCompleteRateFilterTime"`
+	UserTradeCompleteCountMin       int                            `json:"userTradeCompleteCountMin"`
+	UserTradeCompleteRateMin        Amount                         `json:"userTradeCompleteRateMin"`
+	UserTradeVolumeFilterTime       int                            `json:"userTradeVolumeFilterTime"`
+	UserTradeType                   int                            `json:"userTradeType"`
+	UserTradeVolumeMin              Amount                         `json:"userTradeVolumeMin"`
+	UserTradeVolumeMax              Amount                         `json:"userTradeVolumeMax"`
+	UserTradeVolumeAsset            string                         `json:"userTradeVolumeAsset"`
+	CreateTime                      Timestamp                      `json:"createTime"`
+	AdvUpdateTime                   Timestamp                      `json:"advUpdateTime"`
+	FiatVo                          json.RawMessage                `json:"fiatVo"`
+	AssetVo                         json.RawMessage                `json:"assetVo"`
+	AdvVisibleRet                   json.RawMessage                `json:"advVisibleRet"`
+	AssetLogo                       string                         `json:"assetLogo"`
+	AssetScale                      int                            `json:"assetScale"`
+	FiatScale                       int                            `json:"fiatScale"`
+	PriceScale                      int                            `json:"priceScale"`
+	FiatSymbol                      string                         `json:"fiatSymbol"`
+	IsTradable                      bool                           `json:"isTradable"`
+	DynamicMaxSingleTransAmount     Amount                         `json:"dynamicMaxSingleTransAmount"`
+	MinSingleTransQuantity          Amount                         `json:"minSingleTransQuantity"`
+	MaxSingleTransQuantity          Amount                         `json:"maxSingleTransQuantity"`
+	DynamicMaxSingleTransQuantity   Amount                         `json:"dynamicMaxSingleTransQuantity"`
+	TradableQuantity                Amount                         `json:"tradableQuantity"`
+	CommissionRate                  Amount                         `json:"commissionRate"`
+	TradeMethodCommissionRates      []P2PTradeMethodCommissionRate `json:"tradeMethodCommissionRates"`
+	LaunchCountry                   string                         `json:"launchCountry"`
+	AbnormalStatusList              json.RawMessage                `json:"abnormalStatusList"`
+	CloseReason                     string                         `json:"closeReason"`
+	StoreInformation                json.RawMessage                `json:"storeInformation"`
+}
+
+type P2PAdvertiser struct {
+	UserNo             string    `json:"userNo"`
+	RealName           string    `json:"realName"`
+	NickName           string    `json:"nickName"`
+	Margin             Amount    `json:"margin"`
+	MarginUnit         string    `json:"marginUnit"`
+	OrderCount         int       `json:"orderCount"`
+	MonthOrderCount    int       `json:"monthOrderCount"`
+	MonthFinishRate    float64   `json:"monthFinishRate"`
+	PositiveRate       float64   `json:"positiveRate"`
+	AdvConfirmTime     int       `json:"advConfirmTime"`
+	Email              string    `json:"email"`
+	RegistrationTime   Timestamp `json:"registrationTime"`
+	Mobile             string    `json:"mobile"`
+	UserType           UserType  `json:"userType"`
+	TagIconUrls        []string  `json:"tagIconUrls"`
+	UserGrade          int       `json:"userGrade"`
+	UserIdentity       string    `json:"userIdentity"`
+	ProMerchant        bool      `json:"proMerchant"`
+	IsBlocked          bool      `json:"isBlocked"`
+	ActiveTimeInSecond int       `json:"activeTimeInSecond"`
+}
+
 type P2PData struct {
-	Adv struct {
-		AdvNo                 string `json:"advNo"`
-		Classify              string `json:"classify"`
-		TradeType             string `json:"tradeType"`
-		Asset                 string `json:"asset"`
-		FiatUnit              string `json:"fiatUnit"`
-		AdvStatus             any    `json:"advStatus"`
-		PriceType             any    `json:"priceType"`
-		PriceFloatingRatio    any    `json:"priceFloatingRatio"`
-		RateFloatingRatio     any    `json:"rateFloatingRatio"`
-		CurrencyRate          any    `json:"currencyRate"`
-		Price                 string `json:"price"`
-		InitAmount            any    `json:"initAmount"`
-		SurplusAmount         string `json:"surplusAmount"`
-		AmountAfterEditing    any    `json:"amountAfterEditing"`
-		MaxSingleTransAmount  string `json:"maxSingleTransAmount"`
-		MinSingleTransAmount  string `json:"minSingleTransAmount"`
-		BuyerKycLimit         any    `json:"buyerKycLimit"`
-		BuyerRegDaysLimit     any    `json:"buyerRegDaysLimit"`
-		BuyerBtcPositionLimit any    `json:"buyerBtcPositionLimit"`
-		Remarks               any    `json:"remarks"`
-		AutoReplyMsg          string `json:"autoReplyMsg"`
-		PayTimeLimit          int    `json:"payTimeLimit"`
-		TradeMethods          []struct {
-			PayID                any    `json:"payId"`
-			PayMethodID          string `json:"payMethodId"`
-			PayType              any    `json:"payType"`
-			PayAccount           any    `json:"payAccount"`
-			PayBank              any    `json:"payBank"`
-			PaySubBank           any    `json:"paySubBank"`
-			Identifier           string `json:"identifier"`
-			IconURLColor         any    `json:"iconUrlColor"`
-			TradeMethodName      string `json:"tradeMethodName"`
-			TradeMethodShortName string `json:"tradeMethodShortName"`
-			TradeMethodBgColor   string `json:"tradeMethodBgColor"`
-		} `json:"tradeMethods"`
-		UserTradeCountFilterTime        any    `json:"userTradeCountFilterTime"`
-		UserBuyTradeCountMin            any    `json:"userBuyTradeCountMin"`
-		UserBuyTradeCountMax            any    `json:"userBuyTradeCountMax"`
-		UserSellTradeCountMin           any    `json:"userSellTradeCountMin"`
-		UserSellTradeCountMax           any    `json:"userSellTradeCountMax"`
-		UserAllTradeCountMin            any    `json:"userAllTradeCountMin"`
-		UserAllTradeCountMax            any    `json:"userAllTradeCountMax"`
-		UserTradeCompleteRateFilterTime any    `json:"userTradeCompleteRateFilterTime"`
-		UserTradeCompleteCountMin       any    `json:"userTradeCompleteCountMin"`
-		UserTradeCompleteRateMin        any    `json:"userTradeCompleteRateMin"`
-		UserTradeVolumeFilterTime       any    `json:"userTradeVolumeFilterTime"`
-		UserTradeType                   any    `json:"userTradeType"`
-		UserTradeVolumeMin              any    `json:"userTradeVolumeMin"`
-		UserTradeVolumeMax              any    `json:"userTradeVolumeMax"`
-		UserTradeVolumeAsset            any    `json:"userTradeVolumeAsset"`
-		CreateTime                      any    `json:"createTime"`
-		AdvUpdateTime                   any    `json:"advUpdateTime"`
-		FiatVo                          any    `json:"fiatVo"`
-		AssetVo                         any    `json:"assetVo"`
-		AdvVisibleRet                   any    `json:"advVisibleRet"`
-		AssetLogo                       any    `json:"assetLogo"`
-		AssetScale                      int    `json:"assetScale"`
-		FiatScale                       int    `json:"fiatScale"`
-		PriceScale                      int    `json:"priceScale"`
-		FiatSymbol                      string `json:"fiatSymbol"`
-		IsTradable                      bool   `json:"isTradable"`
-		DynamicMaxSingleTransAmount     string `json:"dynamicMaxSingleTransAmount"`
-		MinSingleTransQuantity          string `json:"minSingleTransQuantity"`
-		MaxSingleTransQuantity          string `json:"maxSingleTransQuantity"`
-		DynamicMaxSingleTransQuantity   string `json:"dynamicMaxSingleTransQuantity"`
-		TradableQuantity                string `json:"tradableQuantity"`
-		CommissionRate                  string `json:"commissionRate"`
-		TradeMethodCommissionRates      []any  `json:"tradeMethodCommissionRates"`
-		LaunchCountry                   any    `json:"launchCountry"`
-		AbnormalStatusList              any    `json:"abnormalStatusList"`
-		CloseReason                     any    `json:"closeReason"`
-		StoreInformation                any    `json:"storeInformation"`
-	} `json:"adv"`
-	Advertiser struct {
-		UserNo             string  `json:"userNo"`
-		RealName           string  `json:"realName"`
-		NickName           string  `json:"nickName"`
-		Margin             any     `json:"margin"`
-		MarginUnit         any     `json:"marginUnit"`
-		OrderCount         any     `json:"orderCount"`
-		MonthOrderCount    int     `json:"monthOrderCount"`
-		MonthFinishRate    float64 `json:"monthFinishRate"`
-		PositiveRate       float64 `json:"positiveRate"`
-		AdvConfirmTime     any     `json:"advConfirmTime"`
-		Email              any     `json:"email"`
-		RegistrationTime   any     `json:"registrationTime"`
-		Mobile             any     `json:"mobile"`
-		UserType           string  `json:"userType"`
-		TagIconUrls        []any   `json:"tagIconUrls"`
-		UserGrade          int     `json:"userGrade"`
-		UserIdentity       string  `json:"userIdentity"`
-		ProMerchant        any     `json:"proMerchant"`
-		IsBlocked          any     `json:"isBlocked"`
-		ActiveTimeInSecond int     `json:"activeTimeInSecond"`
-	} `json:"advertiser"`
+	Adv        P2PAdv        `json:"adv"`
+	Advertiser P2PAdvertiser `json:"advertiser"`
 }
 
 type P2PAdvResponse struct {
@@ -125,43 +145,150 @@ type P2PAdvResponse struct {
 }
 
 type P2PAdvRequest struct {
-	ProMerchantAds bool   `json:"proMerchantAds"`
-	Page           int    `json:"page,omitempty"`
-	Rows           int    `json:"rows,omitempty"`
-	PayTypes       []any  `json:"payTypes,omitempty"`
-	Countries      []any  `json:"countries,omitempty"`
-	PublisherType  any    `json:"publisherType,omitempty"`
-	Asset          string `json:"asset,omitempty"`
-	Fiat           string `json:"fiat,omitempty"`
-	TradeType      string `json:"tradeType,omitempty"`
+	ProMerchantAds bool     `json:"proMerchantAds"`
+	Page           int      `json:"page,omitempty"`
+	Rows           int      `json:"rows,omitempty"`
+	PayTypes       []string `json:"payTypes,omitempty"`
+	Countries      []string `json:"countries,omitempty"`
+	PublisherType  string   `json:"publisherType,omitempty"`
+	Asset          string   `json:"asset,omitempty"`
+	Fiat           string   `json:"fiat,omitempty"`
+	TradeType      string   `json:"tradeType,omitempty"`
+	TransAmount    string   `json:"transAmount,omitempty"`
 }
 
-type Client struct{}
+// TradeType : side of the trade, from the point of view of who is looking for ads.
+// TradeTypeBuy returns ads of advertisers selling the asset, TradeTypeSell ads of advertisers buying it.
+type TradeType string
+
+const (
+	TradeTypeBuy  TradeType = "BUY"
+	TradeTypeSell TradeType = "SELL"
+)
+
+// ListOptions : options used by ListP2PAdvs to select and walk through result pages
+type ListOptions struct {
+	// TradeType : defaults to TradeTypeBuy
+	TradeType TradeType
+	// PayTypes : payment method identifiers (e.g. "BANK", "LINEPay"). Empty means any
+	PayTypes []string
+	// Countries : advertiser countries. Empty means any
+	Countries []string
+	// PublisherType : "merchant" to only get ads from merchants. Empty means any
+	PublisherType string
+	// ProMerchantAds : only get ads from pro merchants
+	ProMerchantAds bool
+	// TransAmount : fiat amount we intend to trade. Only ads that can fill it are returned. 0 means any
+	TransAmount float64
+	// MaxPages : max amount of pages to fetch. Values < 1 are treated as 1
+	MaxPages int
+	// MaxAds : max amount of ads to return. Values < 1 mean no limit other than MaxPages
+	MaxAds int
+}
+
+type Client struct {
+	BaseURL    string
+	HTTPClient httpclient.Client
+}
+
+// ListP2PAdvs : list ads for the specified asset and fiat, walking through result pages until all of them are fetched
+// or the limits in opts are reached. Ads are de-duplicated by AdvNo, since they can move across pages between two
+// consecutive requests.
+func (c *Client) ListP2PAdvs(ctx context.Context, asset, fiat string, opts ListOptions) ([]P2PData, error) {
+	maxPages := opts.MaxPages
+	if maxPages < 1 {
+		maxPages = 1
+	}
+
+	advs := make([]P2PData, 0, MaxRowsPerPage)
+	seen := make(map[string]struct{})
+
+	for page := 1; page <= maxPages; page++ {
+		if page > 1 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(PageDelay):
+			}
+		}
+
+		r, err := c.listP2PAdvsPage(ctx, asset, fiat, opts, page)
+		if err != nil {
+			// when throttled, stop here so that the caller can back off
+			if page == 1 || httpclient.IsThrottled(err) {
+				return nil, err
+			}
+
+			// we already have some results: better returning a partial list than nothing
+			logger.Default.WithError(err).WithField("page", page).Warn("ListP2PAdvs partial result")
+			return advs, nil
+		}
+
+		for _, adv := range r.Data {
+			if _, ok := seen[adv.Adv.AdvNo]; ok {
+				continue
+			}
+			seen[adv.Adv.AdvNo] = struct{}{}
+
+			advs = append(advs, adv)
+			if opts.MaxAds > 0 && len(advs) >= opts.MaxAds {
+				return advs, nil
+			}
+		}
+
+		if len(r.Data) == 0 || page*MaxRowsPerPage >= r.Total {
+			break
+		}
+	}
+
+	return advs, nil
+}
+
+func (c *Client) listP2PAdvsPage(ctx context.Context, asset, fiat string, opts ListOptions, page int) (*P2PAdvResponse, error) {
+	tradeType := opts.TradeType
+	if tradeType == "" {
+		tradeType = TradeTypeBuy
+	}
 
-func (c *Client) ListP2PAdvs(asset, fiat string) ([]P2PData, error) {
 	req := P2PAdvRequest{
-		Page:      1,
-		Rows:      20,
-		Asset:     asset,
-		Fiat:      fiat,
-		TradeType: "BUY",
+		ProMerchantAds: opts.ProMerchantAds,
+		Page:           page,
+		Rows:           MaxRowsPerPage,
+		PayTypes:       opts.PayTypes,
+		Countries:      opts.Countries,
+		PublisherType:  opts.PublisherType,
+		Asset:          asset,
+		Fiat:           fiat,
+		TradeType:      string(tradeType),
+	}
+	if opts.TransAmount > 0 {
+		req.TransAmount = strconv.FormatFloat(opts.TransAmount, 'f', -1, 64)
 	}
 
 	reqBytes, _ := json.Marshal(req)
-	resp, err := http.Post(
-		fmt.Sprintf("%s/bapi/c2c/v2/friendly/c2c/adv/search", P2PHost),
-		"application/json",
-		io.NopCloser(bytes.NewReader(reqBytes)),
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/bapi/c2c/v2/friendly/c2c/adv/search", c.BaseURL),
+		bytes.NewReader(reqBytes),
 	)
+	if err != nil {
+		return nil, errors.Wrap(err, "ListP2PAdvs invalid request")
+	}
+	httpReq.Header.Add("content-type", "application/json")
 
+	resp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
 		logger.Default.WithError(err).Error("ListP2PAdvs error")
 		return nil, errors.Wrap(err, "ListP2PAdvs error")
 	}
+	defer resp.Body.Close()
 
-	var respBytes []byte
-	if resp.Body != nil {
-		respBytes, _ = io.ReadAll(resp.Body)
+	respBytes, _ := io.ReadAll(resp.Body)
+
+	if err = httpclient.CheckResponse(resp, respBytes); err != nil {
+		logger.Default.Warn("ListP2PAdvs throttled: " + err.Error())
+		return nil, errors.Wrap(err, "ListP2PAdvs throttled")
 	}
 
 	if resp.StatusCode != 200 {
@@ -180,9 +307,13 @@ func (c *Client) ListP2PAdvs(asset, fiat string) ([]P2PData, error) {
 		return nil, errors.New("ListP2PAdvs no success")
 	}
 
-	return r.Data, nil
+	return &r, nil
 }
 
-func NewClient() *Client {
-	return &Client{}
+// NewClient : baseURL is usually P2PHost
+func NewClient(baseURL string, httpClient httpclient.Client) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: httpClient,
+	}
 }
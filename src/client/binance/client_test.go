@@ -0,0 +1,214 @@
+package binance_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"p2p-check/src/client/binance"
+	"p2p-check/src/client/httpclient"
+	"p2p-check/src/internal/testserver"
+	"p2p-check/src/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+
+	os.Exit(m.Run())
+}
+
+// newStubServer : returns a server answering each page request with the corresponding fixture.
+// Received requests are sent to reqChan, if not nil.
+func newStubServer(t *testing.T, statusCode int, reqChan chan binance.P2PAdvRequest, fixtures ...string) *httptest.Server {
+	t.Helper()
+
+	return testserver.New(t, "/bapi/c2c/v2/friendly/c2c/adv/search", statusCode, func(r *http.Request) string {
+		var req binance.P2PAdvRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return ""
+		}
+		if reqChan != nil {
+			reqChan <- req
+		}
+
+		if req.Page < 1 || req.Page > len(fixtures) {
+			return ""
+		}
+
+		return fixtures[req.Page-1]
+	})
+}
+
+func TestClient_ListP2PAdvs(t *testing.T) {
+	server := newStubServer(t, http.StatusOK, nil, "page1.json", "page2.json")
+	c := binance.NewClient(server.URL, server.Client())
+
+	advs, err := c.ListP2PAdvs(context.Background(), "USDT", "JPY", binance.ListOptions{MaxPages: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// page2.json repeats the last ad of page1.json
+	if len(advs) != 25 {
+		t.Fatalf("expected 25 ads, got %d", len(advs))
+	}
+
+	seen := make(map[string]struct{})
+	for _, adv := range advs {
+		if _, ok := seen[adv.Adv.AdvNo]; ok {
+			t.Errorf("duplicated ad %s", adv.Adv.AdvNo)
+		}
+		seen[adv.Adv.AdvNo] = struct{}{}
+	}
+}
+
+func TestClient_ListP2PAdvs_Types(t *testing.T) {
+	server := newStubServer(t, http.StatusOK, nil, "page1.json")
+	c := binance.NewClient(server.URL, server.Client())
+
+	advs, err := c.ListP2PAdvs(context.Background(), "USDT", "JPY", binance.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	adv := advs[0]
+	if adv.Adv.Price != 150 || adv.Adv.SurplusAmount != 1500 || adv.Adv.MinSingleTransAmount != 10000 {
+		t.Errorf("unexpected amounts %+v", adv.Adv)
+	}
+	if adv.Adv.CommissionRate != 0.001 {
+		t.Errorf("expected commission rate 0.001, got %s", adv.Adv.CommissionRate)
+	}
+	if adv.Adv.PriceType != binance.PriceTypeFixed || adv.Adv.TradeType != binance.TradeTypeSell {
+		t.Errorf("unexpected enums %+v", adv.Adv)
+	}
+	if !adv.Adv.CreateTime.Equal(time.UnixMilli(1681200000000)) {
+		t.Errorf("unexpected create time %s", adv.Adv.CreateTime)
+	}
+	if !advs[1].Adv.CreateTime.IsZero() {
+		t.Errorf("expected zero create time, got %s", advs[1].Adv.CreateTime)
+	}
+	if adv.Advertiser.UserType != binance.UserTypeMerchant || adv.Advertiser.OrderCount != 1200 {
+		t.Errorf("unexpected advertiser %+v", adv.Advertiser)
+	}
+	if adv.Advertiser.RegistrationTime.Year() != 2020 {
+		t.Errorf("unexpected registration time %s", adv.Advertiser.RegistrationTime)
+	}
+}
+
+func TestClient_ListP2PAdvs_Limits(t *testing.T) {
+	server := newStubServer(t, http.StatusOK, nil, "page1.json", "page2.json")
+	c := binance.NewClient(server.URL, server.Client())
+
+	advs, err := c.ListP2PAdvs(context.Background(), "USDT", "JPY", binance.ListOptions{MaxPages: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(advs) != binance.MaxRowsPerPage {
+		t.Errorf("expected %d ads with MaxPages=1, got %d", binance.MaxRowsPerPage, len(advs))
+	}
+
+	advs, err = c.ListP2PAdvs(context.Background(), "USDT", "JPY", binance.ListOptions{MaxPages: 5, MaxAds: 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(advs) != 7 {
+		t.Errorf("expected 7 ads with MaxAds=7, got %d", len(advs))
+	}
+}
+
+func TestClient_ListP2PAdvs_Request(t *testing.T) {
+	reqChan := make(chan binance.P2PAdvRequest, 1)
+	server := newStubServer(t, http.StatusOK, reqChan, "page1.json")
+	c := binance.NewClient(server.URL, server.Client())
+
+	_, err := c.ListP2PAdvs(context.Background(), "USDT", "JPY", binance.ListOptions{
+		TradeType:     binance.TradeTypeSell,
+		PayTypes:      []string{"BANK"},
+		PublisherType: "merchant",
+		TransAmount:   50000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	req := <-reqChan
+	if req.Asset != "USDT" || req.Fiat != "JPY" || req.TradeType != "SELL" || req.Page != 1 {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if len(req.PayTypes) != 1 || req.PayTypes[0] != "BANK" || req.PublisherType != "merchant" {
+		t.Errorf("filters not sent: %+v", req)
+	}
+	if req.TransAmount != "50000" {
+		t.Errorf("expected transAmount 50000, got %s", req.TransAmount)
+	}
+}
+
+func TestClient_ListP2PAdvs_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		fixture    string
+	}{
+		{name: "non 200", statusCode: http.StatusInternalServerError, fixture: "page1.json"},
+		{name: "no success", statusCode: http.StatusOK, fixture: "no_success.json"},
+		{name: "malformed JSON", statusCode: http.StatusOK, fixture: "malformed.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStubServer(t, tt.statusCode, nil, tt.fixture)
+			c := binance.NewClient(server.URL, server.Client())
+
+			advs, err := c.ListP2PAdvs(context.Background(), "USDT", "JPY", binance.ListOptions{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if advs != nil {
+				t.Errorf("expected no ads, got %d", len(advs))
+			}
+		})
+	}
+}
+
+func TestClient_ListP2PAdvs_Throttled(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		fixture    string
+		wantKind   error
+	}{
+		{name: "rate limited", statusCode: http.StatusTooManyRequests, fixture: "no_success.json", wantKind: httpclient.ErrRateLimited},
+		{name: "captcha", statusCode: http.StatusOK, fixture: "captcha.html", wantKind: httpclient.ErrBlocked},
+		{name: "upstream down", statusCode: http.StatusServiceUnavailable, fixture: "captcha.html", wantKind: httpclient.ErrUpstreamDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStubServer(t, tt.statusCode, nil, tt.fixture)
+			c := binance.NewClient(server.URL, server.Client())
+
+			_, err := c.ListP2PAdvs(context.Background(), "USDT", "JPY", binance.ListOptions{})
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("expected %s, got %v", tt.wantKind, err)
+			}
+		})
+	}
+}
+
+func TestClient_ListP2PAdvs_Canceled(t *testing.T) {
+	server := newStubServer(t, http.StatusOK, nil, "page1.json")
+	c := binance.NewClient(server.URL, server.Client())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.ListP2PAdvs(ctx, "USDT", "JPY", binance.ListOptions{}); err == nil {
+		t.Fatal("expected error on canceled context")
+	}
+}
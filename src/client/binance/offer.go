@@ -0,0 +1,77 @@
+package binance
+
+import (
+	"strings"
+	"time"
+
+	"p2p-check/src/offer"
+)
+
+// Venue : name used to identify Binance offers
+const Venue = "binance"
+
+// ToOffer : convert the Binance ad into a venue-neutral offer.Offer
+func (d *P2PData) ToOffer() offer.Offer {
+	// adv.TradeType is the side of the advertiser, which is the opposite of ours
+	side := offer.SideBuy
+	if d.Adv.TradeType == TradeTypeBuy {
+		side = offer.SideSell
+	}
+
+	methodCommissionRates := make(map[string]float64, len(d.Adv.TradeMethodCommissionRates))
+	for _, r := range d.Adv.TradeMethodCommissionRates {
+		methodCommissionRates[r.TradeMethodIdentifier] = r.CommissionRate.Float64()
+	}
+
+	methods := make([]offer.PaymentMethod, 0, len(d.Adv.TradeMethods))
+	for _, m := range d.Adv.TradeMethods {
+		methods = append(methods, offer.PaymentMethod{
+			Identifier:     m.Identifier,
+			Name:           m.TradeMethodName,
+			CommissionRate: methodCommissionRates[m.Identifier],
+		})
+	}
+
+	// the dynamic max takes into account the current balance of the advertiser
+	maxAmount := d.Adv.MaxSingleTransAmount.Float64()
+	if dynamicMax := d.Adv.DynamicMaxSingleTransAmount.Float64(); dynamicMax > 0 && dynamicMax < maxAmount {
+		maxAmount = dynamicMax
+	}
+
+	var lastActiveAt time.Time
+	// Binance sends -1 when the information is not available
+	if d.Advertiser.ActiveTimeInSecond >= 0 {
+		lastActiveAt = time.Now().Add(-time.Duration(d.Advertiser.ActiveTimeInSecond) * time.Second)
+	}
+
+	return offer.Offer{
+		Venue:            Venue,
+		ID:               d.Adv.AdvNo,
+		Side:             side,
+		Asset:            d.Adv.Asset,
+		Fiat:             d.Adv.FiatUnit,
+		Price:            d.Adv.Price.Float64(),
+		AvailableAmount:  d.Adv.SurplusAmount.Float64(),
+		MinAmount:        d.Adv.MinSingleTransAmount.Float64(),
+		MaxAmount:        maxAmount,
+		Tradable:         d.Adv.IsTradable,
+		CommissionRate:   d.Adv.CommissionRate.Float64(),
+		PaymentMethods:   methods,
+		Remarks:          d.Adv.Remarks,
+		AutoReplyMessage: d.Adv.AutoReplyMsg,
+		Advertiser: offer.Advertiser{
+			ID:              d.Advertiser.UserNo,
+			Nickname:        d.Advertiser.NickName,
+			IsMerchant:      d.Advertiser.UserType == UserTypeMerchant || strings.Contains(d.Advertiser.UserIdentity, "MERCHANT"),
+			MonthOrderCount: d.Advertiser.MonthOrderCount,
+			MonthFinishRate: d.Advertiser.MonthFinishRate,
+			PositiveRate:    d.Advertiser.PositiveRate,
+			TotalOrderCount: -1, // not provided in ad listings
+			TotalFinishRate: -1,
+			RegisteredAt:    d.Advertiser.RegistrationTime.Time,
+			LastActiveAt:    lastActiveAt,
+		},
+		CreatedAt: d.Adv.CreateTime.Time,
+		UpdatedAt: d.Adv.AdvUpdateTime.Time,
+	}
+}
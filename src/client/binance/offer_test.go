@@ -0,0 +1,48 @@
+package binance_test
+
+import (
+	"testing"
+
+	"p2p-check/src/client/binance"
+	"p2p-check/src/offer"
+)
+
+func TestP2PData_ToOffer(t *testing.T) {
+	var d binance.P2PData
+	d.Adv.AdvNo = "11"
+	d.Adv.TradeType = binance.TradeTypeSell
+	d.Adv.Asset = "USDT"
+	d.Adv.FiatUnit = "JPY"
+	d.Adv.Price = 150.5
+	d.Adv.SurplusAmount = 1000
+	d.Adv.MinSingleTransAmount = 10000
+	d.Adv.MaxSingleTransAmount = 150000
+	d.Adv.DynamicMaxSingleTransAmount = 120000
+	d.Adv.IsTradable = true
+	d.Adv.TradeMethods = []binance.P2PTradeMethod{{Identifier: "BANK", TradeMethodName: "Bank Transfer"}}
+	d.Advertiser.UserNo = "u11"
+	d.Advertiser.NickName = "bob"
+	d.Advertiser.UserType = binance.UserTypeMerchant
+	d.Advertiser.ActiveTimeInSecond = -1
+
+	o := d.ToOffer()
+
+	if o.Venue != binance.Venue || o.ID != "11" || o.Asset != "USDT" || o.Fiat != "JPY" {
+		t.Errorf("unexpected offer %+v", o)
+	}
+	// the advertiser sells, so we buy
+	if o.Side != offer.SideBuy {
+		t.Errorf("expected side %s, got %s", offer.SideBuy, o.Side)
+	}
+	// the dynamic max is lower than the configured one
+	if o.Price != 150.5 || o.AvailableAmount != 1000 || o.MinAmount != 10000 || o.MaxAmount != 120000 || !o.Tradable {
+		t.Errorf("unexpected amounts %+v", o)
+	}
+	if len(o.PaymentMethods) != 1 || o.PaymentMethods[0].Identifier != "BANK" {
+		t.Errorf("unexpected payment methods %+v", o.PaymentMethods)
+	}
+	if o.Advertiser.ID != "u11" || !o.Advertiser.IsMerchant || !o.Advertiser.LastActiveAt.IsZero() ||
+		o.Advertiser.TotalOrderCount >= 0 || o.Advertiser.TotalFinishRate >= 0 {
+		t.Errorf("unexpected advertiser %+v", o.Advertiser)
+	}
+}
@@ -0,0 +1,95 @@
+package binance
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Amount : decimal amount (price, quantity, ratio...). Binance sends them either as strings or as numbers
+type Amount float64
+
+func (a Amount) Float64() float64 {
+	return float64(a)
+}
+
+func (a Amount) String() string {
+	return strconv.FormatFloat(float64(a), 'f', -1, 64)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		*a = 0
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid amount '%s'", string(b))
+	}
+
+	*a = Amount(f)
+	return nil
+}
+
+// Timestamp : time sent by Binance as milliseconds since epoch
+type Timestamp struct {
+	time.Time
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(t.UnixMilli())
+}
+
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	ms, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timestamp '%s'", string(b))
+	}
+
+	t.Time = time.UnixMilli(ms)
+	return nil
+}
+
+// PriceType : how the price of an ad is defined
+type PriceType int
+
+const (
+	PriceTypeFixed    PriceType = 1
+	PriceTypeFloating PriceType = 2
+)
+
+// UserType : type of advertiser
+type UserType string
+
+const (
+	UserTypeUser     UserType = "user"
+	UserTypeMerchant UserType = "merchant"
+)
+
+// Classify : category of an ad
+type Classify string
+
+const (
+	ClassifyMass       Classify = "mass"
+	ClassifyProfession Classify = "profession"
+	ClassifyFiatTrade  Classify = "fiat_trade"
+)
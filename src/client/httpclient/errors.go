@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrRateLimited : too many requests were sent, the upstream asks to slow down
+	ErrRateLimited = errors.New("rate limited")
+	// ErrBlocked : the upstream refused the request, usually with an anti-bot (captcha) page
+	ErrBlocked = errors.New("blocked")
+	// ErrUpstreamDown : the upstream is not able to answer
+	ErrUpstreamDown = errors.New("upstream down")
+)
+
+// StatusError : typed error for responses that should not be retried right away.
+// Use errors.Is with ErrRateLimited, ErrBlocked or ErrUpstreamDown to check its kind.
+type StatusError struct {
+	Kind       error
+	StatusCode int
+	// RetryAfter : how long the upstream asked to wait before retrying. 0 if not specified
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (status code %d, retry after %s)", e.Kind, e.StatusCode, e.RetryAfter)
+	}
+
+	return fmt.Sprintf("%s (status code %d)", e.Kind, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Kind
+}
+
+// IsThrottled : true if err means we should stop calling the upstream for a while
+func IsThrottled(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrBlocked) || errors.Is(err, ErrUpstreamDown)
+}
+
+// CheckResponse : returns a *StatusError if resp is a rate-limit, anti-bot or upstream error, nil otherwise.
+// body is needed since some upstreams answer with a 200 HTML captcha page instead of the expected JSON.
+func CheckResponse(resp *http.Response, body []byte) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot:
+		// Binance uses 418 when an IP is banned after ignoring 429s
+		return &StatusError{
+			Kind:       ErrRateLimited,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		// checked before the HTML body, since proxies usually answer with an HTML error page
+		return &StatusError{
+			Kind:       ErrUpstreamDown,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	case resp.StatusCode == http.StatusForbidden || isHTML(resp, body):
+		return &StatusError{
+			Kind:       ErrBlocked,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	return nil
+}
+
+func isHTML(resp *http.Response, body []byte) bool {
+	if strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/html") {
+		return true
+	}
+
+	return bytes.HasPrefix(bytes.ToLower(bytes.TrimSpace(body)), []byte("<"))
+}
+
+// parseRetryAfter : Retry-After can be either a number of seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
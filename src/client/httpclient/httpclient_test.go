@@ -1 +1,71 @@
 package httpclient_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"p2p-check/src/client/httpclient"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		statusCode     int
+		header         http.Header
+		body           string
+		wantKind       error
+		wantRetryAfter time.Duration
+	}{
+		{name: "ok", statusCode: http.StatusOK, body: `{"success":true}`},
+		{name: "not found", statusCode: http.StatusNotFound, body: `{}`},
+		{
+			name:           "too many requests",
+			statusCode:     http.StatusTooManyRequests,
+			header:         http.Header{"Retry-After": []string{"120"}},
+			wantKind:       httpclient.ErrRateLimited,
+			wantRetryAfter: 2 * time.Minute,
+		},
+		{name: "banned", statusCode: http.StatusTeapot, wantKind: httpclient.ErrRateLimited},
+		{name: "forbidden", statusCode: http.StatusForbidden, wantKind: httpclient.ErrBlocked},
+		{
+			name:       "captcha page",
+			statusCode: http.StatusOK,
+			header:     http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+			body:       "<!DOCTYPE html><html><body>captcha</body></html>",
+			wantKind:   httpclient.ErrBlocked,
+		},
+		{name: "bad gateway", statusCode: http.StatusBadGateway, wantKind: httpclient.ErrUpstreamDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+
+			err := httpclient.CheckResponse(&http.Response{StatusCode: tt.statusCode, Header: header}, []byte(tt.body))
+			if tt.wantKind == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("expected %s, got %v", tt.wantKind, err)
+			}
+			if !httpclient.IsThrottled(errors.Wrap(err, "wrapped")) {
+				t.Errorf("expected wrapped error to be throttled")
+			}
+
+			var statusErr *httpclient.StatusError
+			if errors.As(err, &statusErr) && statusErr.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("expected retry after %s, got %s", tt.wantRetryAfter, statusErr.RetryAfter)
+			}
+		})
+	}
+}
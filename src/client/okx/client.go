@@ -0,0 +1,176 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"p2p-check/src/client/httpclient"
+	"p2p-check/src/logger"
+)
+
+const (
+	Host = "https://www.okx.com"
+)
+
+// Side : side of the ads, from the point of view of the advertiser.
+// SideSell returns ads of advertisers selling the asset, SideBuy ads of advertisers buying it.
+type Side string
+
+const (
+	SideBuy  Side = "buy"
+	SideSell Side = "sell"
+)
+
+type Ad struct {
+	ID                     string   `json:"id"`
+	Side                   Side     `json:"side"`
+	BaseCurrency           string   `json:"baseCurrency"`
+	QuoteCurrency          string   `json:"quoteCurrency"`
+	Price                  string   `json:"price"`
+	AvailableAmount        string   `json:"availableAmount"`
+	QuoteMinAmountPerOrder string   `json:"quoteMinAmountPerOrder"`
+	QuoteMaxAmountPerOrder string   `json:"quoteMaxAmountPerOrder"`
+	PaymentMethods         []string `json:"paymentMethods"`
+	NickName               string   `json:"nickName"`
+	MerchantID             string   `json:"merchantId"`
+	PublicUserID           string   `json:"publicUserId"`
+	CreatorType            string   `json:"creatorType"`
+	UserType               string   `json:"userType"`
+	CompletedOrderQuantity int      `json:"completedOrderQuantity"`
+	CancelledOrderQuantity int      `json:"cancelledOrderQuantity"`
+	CompletedRate          string   `json:"completedRate"`
+	QuoteScale             int      `json:"quoteScale"`
+	QuoteSymbol            string   `json:"quoteSymbol"`
+}
+
+type BooksResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		Buy  []Ad `json:"buy"`
+		Sell []Ad `json:"sell"`
+	} `json:"data"`
+	Msg          string `json:"msg"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// ListOptions : options used by ListAds
+type ListOptions struct {
+	// Side : defaults to SideSell
+	Side Side
+	// PaymentMethod : only return ads accepting this payment method. Empty means any
+	PaymentMethod string
+	// MerchantOnly : only return ads of certified merchants
+	MerchantOnly bool
+}
+
+type Client struct {
+	BaseURL    string
+	HTTPClient httpclient.Client
+}
+
+// ListAds : list ads for the specified base (e.g. USDT) and quote (e.g. JPY) currencies
+func (c *Client) ListAds(ctx context.Context, baseCurrency, quoteCurrency string, opts ListOptions) ([]Ad, error) {
+	side := opts.Side
+	if side == "" {
+		side = SideSell
+	}
+
+	paymentMethod := opts.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = "all"
+	}
+
+	userType := "all"
+	if opts.MerchantOnly {
+		userType = "certified"
+	}
+
+	query := url.Values{}
+	query.Set("baseCurrency", strings.ToLower(baseCurrency))
+	query.Set("quoteCurrency", strings.ToLower(quoteCurrency))
+	query.Set("side", string(side))
+	query.Set("paymentMethod", paymentMethod)
+	query.Set("userType", userType)
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/v3/c2c/tradingOrders/books?%s", c.BaseURL, query.Encode()),
+		nil,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "ListAds invalid request")
+	}
+	httpReq.Header.Add("accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		logger.Default.WithError(err).Error("ListAds error")
+		return nil, errors.Wrap(err, "ListAds error")
+	}
+	defer resp.Body.Close()
+
+	respBytes, _ := io.ReadAll(resp.Body)
+
+	if err = httpclient.CheckResponse(resp, respBytes); err != nil {
+		logger.Default.Warn("ListAds throttled: " + err.Error())
+		return nil, errors.Wrap(err, "ListAds throttled")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Default.
+			WithField("resp", string(respBytes)).
+			WithField("status-code", resp.StatusCode).
+			Error("ListAds status code != 200")
+		return nil, errors.New("ListAds status code != 200")
+	}
+
+	var r BooksResponse
+	if err = json.Unmarshal(respBytes, &r); err != nil {
+		logger.Default.WithField("resp", string(respBytes)).Error("ListAds invalid resp")
+		return nil, errors.New("ListAds invalid resp")
+	}
+
+	if r.Code != 0 {
+		logger.Default.WithField("resp", string(respBytes)).Error("ListAds no success")
+		return nil, errors.Errorf("ListAds no success: %s", r.ErrorMessage)
+	}
+
+	if side == SideBuy {
+		return r.Data.Buy, nil
+	}
+
+	return r.Data.Sell, nil
+}
+
+// parseAmount : OKX sends amounts as strings. Empty strings are treated as 0
+func parseAmount(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// parseRate : OKX sends ratios as strings. Empty or invalid strings are treated as unknown (-1)
+func parseRate(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return -1
+	}
+
+	return f
+}
+
+func NewClient(baseURL string, httpClient httpclient.Client) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: httpClient,
+	}
+}
@@ -0,0 +1,96 @@
+package okx_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"p2p-check/src/client/okx"
+	"p2p-check/src/internal/testserver"
+	"p2p-check/src/logger"
+	"p2p-check/src/offer"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+
+	os.Exit(m.Run())
+}
+
+// newStubServer : returns a server answering with the fixture matching the requested side
+func newStubServer(t *testing.T, statusCode int, fixtures map[okx.Side]string) *httptest.Server {
+	t.Helper()
+
+	return testserver.New(t, "/v3/c2c/tradingOrders/books", statusCode, func(r *http.Request) string {
+		return fixtures[okx.Side(r.URL.Query().Get("side"))]
+	})
+}
+
+func TestClient_ListAds(t *testing.T) {
+	server := newStubServer(t, http.StatusOK, map[okx.Side]string{
+		okx.SideSell: "books_sell.json",
+		okx.SideBuy:  "books_buy.json",
+	})
+	c := okx.NewClient(server.URL, server.Client())
+
+	ads, err := c.ListAds(context.Background(), "USDT", "JPY", okx.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ads) != 5 {
+		t.Fatalf("expected 5 sell ads, got %d", len(ads))
+	}
+
+	o := ads[1].ToOffer()
+	if o.Venue != okx.Venue || o.Side != offer.SideBuy || o.Asset != "USDT" || o.Fiat != "JPY" {
+		t.Errorf("unexpected offer %+v", o)
+	}
+	if o.Price != 150.25 || o.AvailableAmount != 1250 || o.MinAmount != 10000 || o.MaxAmount != 300000 {
+		t.Errorf("unexpected amounts %+v", o)
+	}
+	if len(o.PaymentMethods) != 2 || o.Advertiser.Nickname != "okx-seller1" {
+		t.Errorf("unexpected offer %+v", o)
+	}
+	// all-time stats are not mapped to the 30 days ones
+	if o.Advertiser.MonthOrderCount >= 0 || o.Advertiser.MonthFinishRate >= 0 || o.Advertiser.PositiveRate >= 0 {
+		t.Errorf("expected unknown advertiser stats, got %+v", o.Advertiser)
+	}
+	if o.Advertiser.TotalOrderCount != 310 || o.Advertiser.TotalFinishRate != 0.9801 {
+		t.Errorf("unexpected all-time stats %+v", o.Advertiser)
+	}
+
+	ads, err = c.ListAds(context.Background(), "USDT", "JPY", okx.ListOptions{Side: okx.SideBuy})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ads) != 3 {
+		t.Fatalf("expected 3 buy ads, got %d", len(ads))
+	}
+	if o = ads[0].ToOffer(); o.Side != offer.SideSell {
+		t.Errorf("expected side %s, got %s", offer.SideSell, o.Side)
+	}
+}
+
+func TestClient_ListAds_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		fixture    string
+	}{
+		{name: "non 200", statusCode: http.StatusBadGateway, fixture: "books_sell.json"},
+		{name: "error code", statusCode: http.StatusOK, fixture: "error.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStubServer(t, tt.statusCode, map[okx.Side]string{okx.SideSell: tt.fixture})
+			c := okx.NewClient(server.URL, server.Client())
+
+			if _, err := c.ListAds(context.Background(), "USDT", "JPY", okx.ListOptions{}); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
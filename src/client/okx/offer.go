@@ -0,0 +1,65 @@
+package okx
+
+import (
+	"strings"
+
+	"p2p-check/src/offer"
+)
+
+// Venue : name used to identify OKX offers
+const Venue = "okx"
+
+// paymentMethodIdentifiers : OKX payment method names mapped to the identifiers used by Binance, so that the same
+// configuration can be used for both venues. Unknown names are kept as they are.
+var paymentMethodIdentifiers = map[string]string{
+	"bank":     "BANK",
+	"LINE Pay": "LINEPay",
+	"PayPay":   "PayPay",
+	"Wise":     "Wise",
+}
+
+// ToOffer : convert the OKX ad into a venue-neutral offer.Offer
+func (a *Ad) ToOffer() offer.Offer {
+	// ad side is the side of the advertiser, which is the opposite of ours
+	side := offer.SideBuy
+	if a.Side == SideBuy {
+		side = offer.SideSell
+	}
+
+	methods := make([]offer.PaymentMethod, 0, len(a.PaymentMethods))
+	for _, m := range a.PaymentMethods {
+		identifier, ok := paymentMethodIdentifiers[m]
+		if !ok {
+			identifier = m
+		}
+
+		methods = append(methods, offer.PaymentMethod{
+			Identifier: identifier,
+			Name:       m,
+		})
+	}
+
+	return offer.Offer{
+		Venue:           Venue,
+		ID:              a.ID,
+		Side:            side,
+		Asset:           strings.ToUpper(a.BaseCurrency),
+		Fiat:            strings.ToUpper(a.QuoteCurrency),
+		Price:           parseAmount(a.Price),
+		AvailableAmount: parseAmount(a.AvailableAmount),
+		MinAmount:       parseAmount(a.QuoteMinAmountPerOrder),
+		MaxAmount:       parseAmount(a.QuoteMaxAmountPerOrder),
+		Tradable:        true, // OKX only returns ads that can be traded
+		PaymentMethods:  methods,
+		Advertiser: offer.Advertiser{
+			ID:              a.MerchantID,
+			Nickname:        a.NickName,
+			IsMerchant:      a.CreatorType == "certified",
+			MonthOrderCount: -1, // OKX only provides all-time stats
+			MonthFinishRate: -1,
+			PositiveRate:    -1, // not provided by OKX
+			TotalOrderCount: a.CompletedOrderQuantity,
+			TotalFinishRate: parseRate(a.CompletedRate),
+		},
+	}
+}
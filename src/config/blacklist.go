@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	// legacyLineMethod : payment method the legacy "line" blacklist referred to
+	legacyLineMethod = "LINEPay"
+	// legacyBankMethod : payment method the legacy "bank" blacklist referred to
+	legacyBankMethod = "BANK"
+)
+
+// AdvertiserRef : reference to an advertiser
+type AdvertiserRef struct {
+	// Venue : marketplace UserNo belongs to
+	Venue string `json:"venue,omitempty"`
+	// UserNo : venue user identifier, stable across nickname changes. Matched before Nickname on Venue
+	UserNo   string `json:"userNo,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+}
+
+// Matches : true if the reference refers to the advertiser with userNo and nickname on venue. User identifiers are
+// only compared on the venue they belong to, nicknames are compared otherwise
+func (r AdvertiserRef) Matches(venue, userNo, nickname string) bool {
+	if r.UserNo != "" && userNo != "" && r.Venue == venue {
+		return r.UserNo == userNo
+	}
+
+	return r.Nickname != "" && r.Nickname == nickname
+}
+
+// BlacklistEntry : advertiser we don't want to trade with
+type BlacklistEntry struct {
+	AdvertiserRef
+	// Methods : payment method identifiers the advertiser is banned for. Empty means all
+	Methods []string  `json:"methods,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	AddedAt time.Time `json:"addedAt"`
+	// ExpiresAt : nil means never
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Bans : true if the entry bans method
+func (e BlacklistEntry) Bans(method string) bool {
+	if len(e.Methods) == 0 {
+		return true
+	}
+
+	for _, m := range e.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsExpired : true if the entry is not valid anymore at t
+func (e BlacklistEntry) IsExpired(t time.Time) bool {
+	return e.ExpiresAt != nil && !t.Before(*e.ExpiresAt)
+}
+
+// Blacklist : can be decoded from the legacy format {"line": [nicknames], "bank": [nicknames]} as well
+type Blacklist []BlacklistEntry
+
+// IsBanned : true if any valid entry bans the advertiser of venue for method
+func (b Blacklist) IsBanned(venue, userNo, nickname, method string) bool {
+	now := time.Now()
+
+	for _, e := range b {
+		if !e.IsExpired(now) && e.Matches(venue, userNo, nickname) && e.Bans(method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemoveExpired : splits b into the entries still valid at t, and the expired ones
+func (b Blacklist) RemoveExpired(t time.Time) (valid, expired Blacklist) {
+	for _, e := range b {
+		if e.IsExpired(t) {
+			expired = append(expired, e)
+		} else {
+			valid = append(valid, e)
+		}
+	}
+
+	return valid, expired
+}
+
+func (b *Blacklist) UnmarshalJSON(data []byte) error {
+	if !isLegacyBlacklist(data) {
+		var entries []BlacklistEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+
+		*b = entries
+		return nil
+	}
+
+	var legacy struct {
+		Line []string `json:"line"`
+		Bank []string `json:"bank"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	entries := make(Blacklist, 0, len(legacy.Line)+len(legacy.Bank))
+	for _, nickname := range legacy.Line {
+		entries = append(entries, BlacklistEntry{AdvertiserRef: AdvertiserRef{Nickname: nickname}, Methods: []string{legacyLineMethod}})
+	}
+	for _, nickname := range legacy.Bank {
+		entries = append(entries, BlacklistEntry{AdvertiserRef: AdvertiserRef{Nickname: nickname}, Methods: []string{legacyBankMethod}})
+	}
+
+	*b = entries
+	return nil
+}
+
+// isLegacyBlacklist : the legacy format is an object, the current one an array
+func isLegacyBlacklist(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"p2p-check/src/config"
+)
+
+func TestBlacklist_UnmarshalJSON(t *testing.T) {
+	var cfg config.Config
+	if err := json.Unmarshal([]byte(`{"blackList": {"line": ["alice"], "bank": ["bob", "carol"]}}`), &cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(cfg.BlackList) != 3 {
+		t.Fatalf("expected 3 migrated entries, got %d", len(cfg.BlackList))
+	}
+	if !cfg.BlackList.IsBanned("", "", "alice", "LINEPay") || cfg.BlackList.IsBanned("", "", "alice", "BANK") {
+		t.Errorf("alice should be banned for LINEPay only")
+	}
+	if !cfg.BlackList.IsBanned("", "", "carol", "BANK") {
+		t.Errorf("carol should be banned for BANK")
+	}
+
+	// the current format survives a round trip
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var decoded config.Config
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(decoded.BlackList) != 3 || decoded.BlackList[1].Nickname != "bob" {
+		t.Errorf("unexpected blacklist %+v", decoded.BlackList)
+	}
+}
+
+func TestBlacklist_IsBanned(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	blacklist := config.Blacklist{
+		{AdvertiserRef: config.AdvertiserRef{Venue: "binance", UserNo: "u1", Nickname: "alice"}, Methods: []string{"BANK"}},
+		{AdvertiserRef: config.AdvertiserRef{Nickname: "bob"}},
+		{AdvertiserRef: config.AdvertiserRef{Venue: "binance", UserNo: "u3", Nickname: "carol"}, ExpiresAt: &expired},
+	}
+
+	tests := []struct {
+		name     string
+		venue    string
+		userNo   string
+		nickname string
+		method   string
+		want     bool
+	}{
+		{name: "listed method", venue: "binance", userNo: "u1", nickname: "alice", method: "BANK", want: true},
+		{name: "other method", venue: "binance", userNo: "u1", nickname: "alice", method: "LINEPay", want: false},
+		{name: "renamed advertiser", venue: "binance", userNo: "u1", nickname: "alice2", method: "BANK", want: true},
+		{name: "same nickname, other user", venue: "binance", userNo: "u9", nickname: "alice", method: "BANK", want: false},
+		// user identifiers are only unique within a venue
+		{name: "same user number, other venue", venue: "okx", userNo: "u1", nickname: "dave", method: "BANK", want: false},
+		{name: "same nickname, other venue", venue: "okx", userNo: "u9", nickname: "alice", method: "BANK", want: true},
+		{name: "all methods", venue: "okx", userNo: "u2", nickname: "bob", method: "PayPay", want: true},
+		{name: "expired", venue: "binance", userNo: "u3", nickname: "carol", method: "BANK", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blacklist.IsBanned(tt.venue, tt.userNo, tt.nickname, tt.method); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBlacklist_RemoveExpired(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	blacklist := config.Blacklist{
+		{AdvertiserRef: config.AdvertiserRef{Nickname: "alice"}},
+		{AdvertiserRef: config.AdvertiserRef{Nickname: "bob"}, ExpiresAt: &past},
+		{AdvertiserRef: config.AdvertiserRef{Nickname: "carol"}, ExpiresAt: &future},
+	}
+
+	valid, expired := blacklist.RemoveExpired(now)
+	if len(valid) != 2 || valid[0].Nickname != "alice" || valid[1].Nickname != "carol" {
+		t.Errorf("unexpected valid entries %+v", valid)
+	}
+	if len(expired) != 1 || expired[0].Nickname != "bob" {
+		t.Errorf("unexpected expired entries %+v", expired)
+	}
+}
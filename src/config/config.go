@@ -1,28 +1,277 @@
 package config
 
+import "p2p-check/src/schedule"
+
+const (
+	// ForexStrategyAggregate : fx rate is the median of the rates of all providers
+	ForexStrategyAggregate = "aggregate"
+	// ForexStrategyFailover : fx rate comes from the first provider, in order, returning it
+	ForexStrategyFailover = "failover"
+)
+
 const (
 	// DefaultMaxSurplusPercentage : P2P ads that are less than this percentage higher that the fx price will be
 	// considered valid
 	DefaultMaxSurplusPercentage = 1
+	// DefaultMinPremiumPercentage : P2P ads (on the SELL side) that are at least this percentage higher that the fx
+	// price will be considered valid
+	DefaultMinPremiumPercentage = 1
+
+	// DefaultFavoriteExtraPercentage : default threshold loosening for Favorites
+	DefaultFavoriteExtraPercentage = 0.5
+
+	// DefaultForexMaxDeviationPercentage : default max deviation of a provider fx rate from the median of all of them
+	DefaultForexMaxDeviationPercentage = 0.5
+
+	// DefaultForexFailoverCooldownMinutes : default amount of minutes a failing fx provider is tried last
+	DefaultForexFailoverCooldownMinutes = 10
+
+	// DefaultForexCacheTTLSeconds : default amount of seconds fx rates are cached for
+	DefaultForexCacheTTLSeconds = 30
+	// DefaultForexMaxStalenessMinutes : default max age of fx rates used to check ads
+	DefaultForexMaxStalenessMinutes = 15
+
+	// DefaultMaxPages : default max amount of P2P result pages fetched for each check
+	DefaultMaxPages = 5
+	// DefaultMaxAds : default max amount of P2P ads fetched for each check
+	DefaultMaxAds = 100
 )
 
+// ForexProvider : fx rate provider
+type ForexProvider struct {
+	// Name : provider name (fastforex, alphavantage)
+	Name string `json:"name"`
+	// APIKey : if empty, read from the {NAME}_API_KEY env variable (e.g. FASTFOREX_API_KEY)
+	APIKey string `json:"apiKey,omitempty"`
+	// BaseURL : provider API URL. Empty means the default one
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+// PaymentMethod : payment method we accept
+type PaymentMethod struct {
+	// Identifier : payment method identifier (e.g. "BANK", "LINEPay", "PayPay")
+	Identifier string `json:"identifier"`
+	// Schedule : when the method can be used. nil means always
+	Schedule *schedule.Schedule `json:"schedule,omitempty"`
+}
+
+// HolidayCalendar : holidays of a country, that payment method schedules can refer to
+type HolidayCalendar struct {
+	// Country : country code (e.g. "JP"). Dates are added to the built-in calendar of the country, if any
+	Country string `json:"country"`
+	// Dates : holidays, as "YYYY-MM-DD"
+	Dates []string `json:"dates"`
+	// Files : paths of ICS (.ics) files, or of JSON (.json) files containing an array of "YYYY-MM-DD" dates
+	Files []string `json:"files"`
+}
+
+// PaymentMethodFee : cost of transferring fiat with a payment method
+type PaymentMethodFee struct {
+	// Identifier : payment method identifier (e.g. "BANK")
+	Identifier string `json:"identifier"`
+	// Fixed : fiat amount charged for each transfer
+	Fixed float64 `json:"fixed"`
+	// Percentage : percentage of the transferred fiat amount
+	Percentage float64 `json:"percentage"`
+}
+
+// AdvertiserFilters : min requirements for advertisers to be notified about. Zero values disable the filter
+type AdvertiserFilters struct {
+	// MinFinishRate : min ratio [0, 1] of orders completed in the last 30 days
+	MinFinishRate float64 `json:"minFinishRate"`
+	// MinPositiveRate : min ratio [0, 1] of positive feedbacks
+	MinPositiveRate float64 `json:"minPositiveRate"`
+	// MinMonthOrders : min amount of orders completed in the last 30 days
+	MinMonthOrders int `json:"minMonthOrders"`
+	// MerchantOnly : only notify about merchants
+	MerchantOnly bool `json:"merchantOnly"`
+	// MaxInactiveMinutes : max amount of minutes since the advertiser was last active
+	MaxInactiveMinutes int `json:"maxInactiveMinutes"`
+	// AllTimeStatsFallback : check MinFinishRate and MinMonthOrders against all-time stats when a venue does not
+	// provide the 30 days ones (OKX)
+	AllTimeStatsFallback bool `json:"allTimeStatsFallback"`
+	// AllowUnknownStats : let advertisers whose venue does not provide a stat pass its filter. Otherwise, they are
+	// ignored as soon as a min is set for that stat
+	AllowUnknownStats bool `json:"allowUnknownStats"`
+}
+
+// RemarkFilters : keywords and regular expressions matched, case-insensitively, against ad remarks and auto-reply
+// messages
+type RemarkFilters struct {
+	// Include : if Include or IncludeRegex are not empty, only ads matching at least one of them are notified
+	Include      []string `json:"include"`
+	IncludeRegex []string `json:"includeRegex"`
+	// Exclude : ads matching any of Exclude or ExcludeRegex are ignored
+	Exclude      []string `json:"exclude"`
+	ExcludeRegex []string `json:"excludeRegex"`
+}
+
 type Config struct {
-	BlackList struct {
-		Line []string `json:"line"`
-		Bank []string `json:"bank"`
-	} `json:"blackList"`
-	// MaxSurplusPercentage :
-	MaxSurplusPercentage float64 `json:"maxSurplusPercentage"`
-	TargetCurrency       string  `json:"targetCurrency"`
+	// BlackList : advertisers we don't want to trade with
+	BlackList Blacklist `json:"blackList"`
+	// Favorites : trusted advertisers. Their ads are notified with looser thresholds (see FavoriteExtraPercentage),
+	// and again as soon as their price improves
+	Favorites Favorites `json:"favorites"`
+	// FavoriteExtraPercentage : percentage points added to MaxSurplusPercentage, and removed from
+	// MinPremiumPercentage, for Favorites. nil means DefaultFavoriteExtraPercentage
+	FavoriteExtraPercentage *float64 `json:"favoriteExtraPercentage,omitempty"`
+	// MaxSurplusPercentage : nil means DefaultMaxSurplusPercentage
+	MaxSurplusPercentage *float64 `json:"maxSurplusPercentage,omitempty"`
+	// MinPremiumPercentage : like MaxSurplusPercentage, but for SELL ads. nil means DefaultMinPremiumPercentage
+	MinPremiumPercentage *float64 `json:"minPremiumPercentage,omitempty"`
+	// TradeTypes : sides to monitor, BUY and/or SELL
+	TradeTypes []string `json:"tradeTypes"`
+	// Assets : crypto assets to monitor (e.g. USDT, USDC, BTC)
+	Assets []string `json:"assets"`
+	// UsePegReference : if true, stablecoins are priced in USD using their actual market price instead of 1:1,
+	// so that depegs are taken into account
+	UsePegReference bool `json:"usePegReference"`
+	// Venues : P2P marketplaces to monitor (binance, okx)
+	Venues         []string `json:"venues"`
+	TargetCurrency string   `json:"targetCurrency"`
+	// ForexProviders : fx rate providers, in priority order
+	ForexProviders []ForexProvider `json:"forexProviders"`
+	// ForexMaxDeviationPercentage : fx rates deviating more than this percentage from the median rate of all
+	// providers are ignored. nil means DefaultForexMaxDeviationPercentage, 0 keeps all of them
+	ForexMaxDeviationPercentage *float64 `json:"forexMaxDeviationPercentage,omitempty"`
+	// ForexStrategy : how fx providers are combined, ForexStrategyAggregate or ForexStrategyFailover
+	ForexStrategy string `json:"forexStrategy"`
+	// ForexFailoverCooldownMinutes : with ForexStrategyFailover, amount of minutes a failing provider is tried last
+	ForexFailoverCooldownMinutes int `json:"forexFailoverCooldownMinutes"`
+	// ForexCacheTTLSeconds : fx rates are fetched again only after being cached for this amount of seconds
+	ForexCacheTTLSeconds int `json:"forexCacheTTLSeconds"`
+	// ForexMaxStalenessMinutes : when providers fail, cached fx rates are used for this amount of minutes at most.
+	// Ads are not checked against older rates
+	ForexMaxStalenessMinutes int `json:"forexMaxStalenessMinutes"`
+	// MaxPages : max amount of P2P result pages fetched for each check
+	MaxPages int `json:"maxPages"`
+	// MaxAds : max amount of P2P ads fetched for each check
+	MaxAds int `json:"maxAds"`
+
+	// the following fields are sent to the P2P marketplaces, so that only relevant ads are returned
+
+	// PayTypes : payment method identifiers (e.g. "BANK", "LINEPay"). Empty means any
+	PayTypes []string `json:"payTypes"`
+	// Countries : advertiser countries. Empty means any
+	Countries []string `json:"countries"`
+	// PublisherType : "merchant" to only get ads from merchants. Empty means any
+	PublisherType string `json:"publisherType"`
+	// ProMerchantAds : only get ads from pro merchants
+	ProMerchantAds bool `json:"proMerchantAds"`
+	// TransAmount : fiat amount we intend to trade. Only ads that can fill it are returned. 0 means any
+	TransAmount float64 `json:"transAmount"`
+
+	// MinTradeAmount : min fiat amount worth trading. Ads whose remaining tradable amount is lower are ignored
+	MinTradeAmount float64 `json:"minTradeAmount"`
+	// AdvertiserFilters : advertisers not meeting these requirements are ignored
+	AdvertiserFilters AdvertiserFilters `json:"advertiserFilters"`
+	// RemarkFilters : filters on ad remarks and auto-reply messages
+	RemarkFilters RemarkFilters `json:"remarkFilters"`
+	// PaymentMethods : only ads with at least one of these methods, currently usable, are notified
+	PaymentMethods []PaymentMethod `json:"paymentMethods"`
+	// HolidayCalendars : holidays in addition to the built-in ones (JP)
+	HolidayCalendars []HolidayCalendar `json:"holidayCalendars"`
+	// PaymentMethodFees : fees included, together with the venue commission, in the effective price of ads
+	PaymentMethodFees []PaymentMethodFee `json:"paymentMethodFees"`
+
+	// FillAmount : fiat budget to trade across several ads. If set, the volume-weighted price of filling it is
+	// notified when good enough. 0 disables the check
+	FillAmount float64 `json:"fillAmount"`
+}
+
+// WithDefaults : copy of c with default fields set, to be used at runtime. Defaults are not meant to be saved, so
+// that they can change without editing the config file
+func (c Config) WithDefaults() Config {
+	c.SetDefault()
+
+	return c
+}
+
+// Float64 : pointer to v, for optional fields
+func Float64(v float64) *float64 {
+	return &v
 }
 
 // SetDefault : set default fields if necessary
 func (c *Config) SetDefault() {
-	if c.MaxSurplusPercentage == 0 {
-		c.MaxSurplusPercentage = DefaultMaxSurplusPercentage
+	if c.MaxSurplusPercentage == nil {
+		c.MaxSurplusPercentage = Float64(DefaultMaxSurplusPercentage)
+	}
+
+	if c.MinPremiumPercentage == nil {
+		c.MinPremiumPercentage = Float64(DefaultMinPremiumPercentage)
+	}
+
+	if c.FavoriteExtraPercentage == nil {
+		c.FavoriteExtraPercentage = Float64(DefaultFavoriteExtraPercentage)
+	}
+
+	if len(c.TradeTypes) == 0 {
+		c.TradeTypes = []string{"BUY"}
+	}
+
+	if len(c.Assets) == 0 {
+		c.Assets = []string{"USDT"}
+	}
+
+	if len(c.Venues) == 0 {
+		c.Venues = []string{"binance"}
 	}
 
 	if c.TargetCurrency == "" {
 		c.TargetCurrency = "JPY"
 	}
+
+	if len(c.ForexProviders) == 0 {
+		c.ForexProviders = []ForexProvider{{Name: "fastforex"}}
+	}
+
+	if c.ForexMaxDeviationPercentage == nil {
+		c.ForexMaxDeviationPercentage = Float64(DefaultForexMaxDeviationPercentage)
+	}
+
+	if c.ForexStrategy == "" {
+		c.ForexStrategy = ForexStrategyAggregate
+	}
+
+	if c.ForexFailoverCooldownMinutes == 0 {
+		c.ForexFailoverCooldownMinutes = DefaultForexFailoverCooldownMinutes
+	}
+
+	if c.ForexCacheTTLSeconds == 0 {
+		c.ForexCacheTTLSeconds = DefaultForexCacheTTLSeconds
+	}
+
+	if c.ForexMaxStalenessMinutes == 0 {
+		c.ForexMaxStalenessMinutes = DefaultForexMaxStalenessMinutes
+	}
+
+	if len(c.PaymentMethods) == 0 {
+		c.PaymentMethods = DefaultPaymentMethods()
+	}
+
+	if c.MaxPages == 0 {
+		c.MaxPages = DefaultMaxPages
+	}
+
+	if c.MaxAds == 0 {
+		c.MaxAds = DefaultMaxAds
+	}
+}
+
+// DefaultPaymentMethods : LINEPay at any time, bank transfers from Monday to Friday from 6:00 to 14:30 Japan time,
+// except on Japanese holidays
+func DefaultPaymentMethods() []PaymentMethod {
+	return []PaymentMethod{
+		{Identifier: "LINEPay"},
+		{
+			Identifier: "BANK",
+			Schedule: &schedule.Schedule{
+				Weekdays:   []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+				TimeRanges: []schedule.TimeRange{{From: "06:00", To: "14:30"}},
+				Timezone:   "Asia/Tokyo",
+				Holidays:   "JP",
+			},
+		},
+	}
 }
@@ -0,0 +1,33 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"p2p-check/src/config"
+)
+
+func TestConfig_WithDefaults(t *testing.T) {
+	var cfg config.Config
+	if err := json.Unmarshal([]byte(`{"maxSurplusPercentage": 0, "assets": ["BTC"]}`), &cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	withDefaults := cfg.WithDefaults()
+
+	// explicit zeros are kept
+	if *withDefaults.MaxSurplusPercentage != 0 {
+		t.Errorf("expected max surplus 0, got %f", *withDefaults.MaxSurplusPercentage)
+	}
+	if *withDefaults.MinPremiumPercentage != config.DefaultMinPremiumPercentage {
+		t.Errorf("expected default min premium, got %f", *withDefaults.MinPremiumPercentage)
+	}
+	if len(withDefaults.Assets) != 1 || withDefaults.Assets[0] != "BTC" || withDefaults.TargetCurrency != "JPY" {
+		t.Errorf("unexpected config %+v", withDefaults)
+	}
+
+	// cfg is left untouched
+	if cfg.MinPremiumPercentage != nil || cfg.TargetCurrency != "" || cfg.PaymentMethods != nil {
+		t.Errorf("defaults set on the original config %+v", cfg)
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// Favorite : trusted advertiser we always want to trade with
+type Favorite struct {
+	AdvertiserRef
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// Favorites : trusted advertisers
+type Favorites []Favorite
+
+// Contains : true if the advertiser of venue is a favorite
+func (f Favorites) Contains(venue, userNo, nickname string) bool {
+	for _, favorite := range f {
+		if favorite.Matches(venue, userNo, nickname) {
+			return true
+		}
+	}
+
+	return false
+}
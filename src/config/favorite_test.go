@@ -0,0 +1,39 @@
+package config_test
+
+import (
+	"testing"
+
+	"p2p-check/src/config"
+)
+
+func TestFavorites_Contains(t *testing.T) {
+	favorites := config.Favorites{
+		{AdvertiserRef: config.AdvertiserRef{Venue: "binance", UserNo: "u1", Nickname: "alice"}},
+		{AdvertiserRef: config.AdvertiserRef{Nickname: "bob"}},
+	}
+
+	tests := []struct {
+		name     string
+		venue    string
+		userNo   string
+		nickname string
+		want     bool
+	}{
+		{name: "same user", venue: "binance", userNo: "u1", nickname: "alice", want: true},
+		{name: "renamed advertiser", venue: "binance", userNo: "u1", nickname: "alice2", want: true},
+		{name: "same nickname, other user", venue: "binance", userNo: "u9", nickname: "alice", want: false},
+		// user identifiers are only unique within a venue
+		{name: "same user number, other venue", venue: "okx", userNo: "u1", nickname: "dave", want: false},
+		{name: "same nickname, other venue", venue: "okx", userNo: "u9", nickname: "alice", want: true},
+		{name: "nickname only", venue: "okx", userNo: "u2", nickname: "bob", want: true},
+		{name: "unknown", venue: "binance", userNo: "u3", nickname: "carol", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := favorites.Contains(tt.venue, tt.userNo, tt.nickname); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
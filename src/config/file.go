@@ -15,6 +15,33 @@ type FileManager struct {
 }
 
 func (m *FileManager) GetConfig() Config {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.readConfig()
+}
+
+func (m *FileManager) SaveConfig(cfg Config) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.writeConfig(cfg)
+}
+
+func (m *FileManager) Update(fn func(cfg *Config) error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	cfg := m.readConfig()
+	if err := fn(&cfg); err != nil {
+		return err
+	}
+	m.writeConfig(cfg)
+
+	return nil
+}
+
+func (m *FileManager) readConfig() Config {
 	// supposing config can be read because this FileManager could be created
 	fileBytes, _ := os.ReadFile(m.configFilePath)
 
@@ -25,10 +52,7 @@ func (m *FileManager) GetConfig() Config {
 	return cfg
 }
 
-func (m *FileManager) SaveConfig(cfg Config) {
-	m.lock.Lock()
-	defer m.lock.Unlock()
-
+func (m *FileManager) writeConfig(cfg Config) {
 	cfgBytes, _ := json.MarshalIndent(cfg, "", "  ")
 	_ = os.WriteFile(m.configFilePath, cfgBytes, 0644)
 }
@@ -51,7 +75,22 @@ func NewFileManager(_ context.Context, configFilePath string) (Manager, error) {
 	if err = json.Unmarshal(fileBytes, &cfg); err != nil {
 		// in case we start with an invalid config, create a default valid one
 		m.SaveConfig(cfg)
+	} else if hasLegacyBlacklist(fileBytes) {
+		// rewrite the blacklist in the current format
+		m.SaveConfig(cfg)
 	}
 
 	return m, nil
 }
+
+// hasLegacyBlacklist : true if the blacklist of the config file is in the legacy {"line": [], "bank": []} format
+func hasLegacyBlacklist(fileBytes []byte) bool {
+	var raw struct {
+		BlackList json.RawMessage `json:"blackList"`
+	}
+	if err := json.Unmarshal(fileBytes, &raw); err != nil {
+		return false
+	}
+
+	return isLegacyBlacklist(raw.BlackList)
+}
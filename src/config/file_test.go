@@ -0,0 +1,89 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"p2p-check/src/config"
+)
+
+func TestFileManager_SaveConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"targetCurrency": "EUR", "maxSurplusPercentage": 0}`), 0644); err != nil {
+		t.Fatalf("cannot write config: %s", err)
+	}
+
+	m, err := config.NewFileManager(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cfg := m.GetConfig()
+	cfg.Favorites = append(cfg.Favorites, config.Favorite{AdvertiserRef: config.AdvertiserRef{Nickname: "alice"}})
+	m.SaveConfig(cfg)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read config: %s", err)
+	}
+
+	// defaults are not persisted, explicit values are
+	for _, field := range []string{"paymentMethods\": [", "maxPages\": 5"} {
+		if strings.Contains(string(b), field) {
+			t.Errorf("default %s saved in %s", field, b)
+		}
+	}
+	for _, field := range []string{"\"maxSurplusPercentage\": 0", "alice", "EUR"} {
+		if !strings.Contains(string(b), field) {
+			t.Errorf("%s not saved in %s", field, b)
+		}
+	}
+}
+
+func TestFileManager_Update(t *testing.T) {
+	m, err := config.NewFileManager(context.Background(), filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// concurrent updates must not overwrite each other
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := m.Update(func(cfg *config.Config) error {
+				nickname := fmt.Sprintf("user%d", i)
+				cfg.Favorites = append(cfg.Favorites, config.Favorite{AdvertiserRef: config.AdvertiserRef{Nickname: nickname}})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if favorites := m.GetConfig().Favorites; len(favorites) != 20 {
+		t.Errorf("expected 20 favorites, got %d", len(favorites))
+	}
+
+	// a failed update is not saved
+	updateErr := errors.New("update failed")
+	err = m.Update(func(cfg *config.Config) error {
+		cfg.Favorites = nil
+		return updateErr
+	})
+	if !errors.Is(err, updateErr) {
+		t.Errorf("expected %s, got %v", updateErr, err)
+	}
+	if favorites := m.GetConfig().Favorites; len(favorites) != 20 {
+		t.Errorf("expected 20 favorites after failed update, got %d", len(favorites))
+	}
+}
@@ -3,4 +3,7 @@ package config
 type Manager interface {
 	GetConfig() Config
 	SaveConfig(cfg Config)
+	// Update : atomically apply fn to the current config and save the result. Nothing is saved if fn returns an error,
+	// which is then returned
+	Update(fn func(cfg *Config) error) error
 }
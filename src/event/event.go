@@ -6,4 +6,5 @@ const (
 	Pause     Event = "pause"
 	Restart   Event = "restart"
 	Blacklist Event = "blacklist"
+	Favorite  Event = "favorite"
 )
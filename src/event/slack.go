@@ -33,6 +33,13 @@ func (s *Slack) execSlashCommand(command, args string) (message string, err erro
 			return "", errors.New("callback for blacklist not implemented")
 		}
 
+		return callback(args)
+	case "/favorite":
+		callback, ok := s.callbacks[Favorite]
+		if !ok {
+			return "", errors.New("callback for favorite not implemented")
+		}
+
 		return callback(args)
 	case "/pause":
 		callback, ok := s.callbacks[Pause]
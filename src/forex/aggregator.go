@@ -0,0 +1,165 @@
+package forex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"p2p-check/src/logger"
+)
+
+// AggregatorName : name of the Aggregator provider
+const AggregatorName = "aggregate"
+
+// Aggregator : queries several providers concurrently and returns the median of their rates, ignoring the ones
+// deviating too much from it
+type Aggregator struct {
+	Providers []Client
+	// MaxDeviationPercentage : rates farther than this percentage from the median of all rates are dropped. 0 keeps
+	// all of them
+	MaxDeviationPercentage float64
+}
+
+func (a *Aggregator) Name() string {
+	return AggregatorName
+}
+
+// GetMaxRate : the aggregator cannot be called more often than its slowest provider
+func (a *Aggregator) GetMaxRate() time.Duration {
+	var maxRate time.Duration
+	for _, p := range a.Providers {
+		if r := p.GetMaxRate(); r > maxRate {
+			maxRate = r
+		}
+	}
+
+	return maxRate
+}
+
+func (a *Aggregator) GetCurrentFxRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	quote, err := GetQuote(ctx, a, fromCurrency, toCurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	return quote.Rate, nil
+}
+
+func (a *Aggregator) GetQuotes(ctx context.Context, fromCurrency string, toCurrencies []string) (map[string]Quote, error) {
+	type result struct {
+		quotes map[string]Quote
+		err    error
+	}
+
+	results := make([]result, len(a.Providers))
+	wg := sync.WaitGroup{}
+	for i, p := range a.Providers {
+		wg.Add(1)
+		go func(i int, p Client) {
+			defer wg.Done()
+
+			quotes, err := GetQuotes(ctx, p, fromCurrency, toCurrencies)
+			results[i] = result{quotes: quotes, err: errors.Wrap(err, p.Name())}
+		}(i, p)
+	}
+	wg.Wait()
+
+	providerQuotes := make([]map[string]Quote, 0, len(results))
+	errs := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err.Error())
+			continue
+		}
+
+		providerQuotes = append(providerQuotes, r.quotes)
+	}
+
+	if len(providerQuotes) == 0 {
+		return nil, fmt.Errorf("no provider returned a rate: %s", strings.Join(errs, "; "))
+	}
+
+	aggregated := make(map[string]Quote, len(toCurrencies))
+	for _, toCurrency := range toCurrencies {
+		currency := strings.ToUpper(toCurrency)
+
+		quotes := make([]Quote, 0, len(providerQuotes))
+		for _, pq := range providerQuotes {
+			quotes = append(quotes, pq[currency])
+		}
+
+		quote, err := a.aggregate(quotes)
+		if err != nil {
+			return nil, errors.Wrap(err, currency)
+		}
+
+		aggregated[currency] = quote
+	}
+
+	return aggregated, nil
+}
+
+// aggregate : median of quotes, which must not be empty, after dropping outliers
+func (a *Aggregator) aggregate(quotes []Quote) (Quote, error) {
+	median := medianRate(quotes)
+
+	kept := make([]Quote, 0, len(quotes))
+	for _, q := range quotes {
+		deviation := math.Abs(q.Rate/median*100 - 100)
+		if a.MaxDeviationPercentage > 0 && deviation > a.MaxDeviationPercentage {
+			logger.Default.
+				WithField("sources", q.Sources).
+				WithField("rate", q.Rate).
+				WithField("median", median).
+				Warn("fx rate dropped as outlier")
+			continue
+		}
+
+		kept = append(kept, q)
+	}
+
+	// no majority, e.g. two providers disagreeing
+	if len(kept) == 0 {
+		return Quote{}, fmt.Errorf("provider rates deviate more than %f%% from their median", a.MaxDeviationPercentage)
+	}
+
+	// the aggregated rate is as old as the oldest rate it comes from
+	aggregated := Quote{Rate: medianRate(kept), FetchedAt: kept[0].FetchedAt}
+	for _, q := range kept {
+		aggregated.Sources = append(aggregated.Sources, q.Sources...)
+		if q.FetchedAt.Before(aggregated.FetchedAt) {
+			aggregated.FetchedAt = q.FetchedAt
+		}
+	}
+
+	return aggregated, nil
+}
+
+// medianRate : median of the rates of quotes, which must not be empty
+func medianRate(quotes []Quote) float64 {
+	rates := make([]float64, 0, len(quotes))
+	for _, q := range quotes {
+		rates = append(rates, q.Rate)
+	}
+	sort.Float64s(rates)
+
+	mid := len(rates) / 2
+	if len(rates)%2 == 0 {
+		return (rates[mid-1] + rates[mid]) / 2
+	}
+
+	return rates[mid]
+}
+
+func NewAggregator(maxDeviationPercentage float64, providers ...Client) Client {
+	return &Aggregator{
+		Providers:              providers,
+		MaxDeviationPercentage: maxDeviationPercentage,
+	}
+}
@@ -0,0 +1,100 @@
+package forex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"p2p-check/src/forex"
+)
+
+func TestAggregator_GetQuote(t *testing.T) {
+	tests := []struct {
+		name         string
+		providers    []forex.Client
+		maxDeviation float64
+		wantRate     float64
+		wantSources  []string
+		wantErr      bool
+	}{
+		{
+			name: "median",
+			providers: []forex.Client{
+				stubClient{name: "a", rate: 150}, stubClient{name: "b", rate: 150.2}, stubClient{name: "c", rate: 150.1},
+			},
+			maxDeviation: 1,
+			wantRate:     150.1,
+			wantSources:  []string{"a", "b", "c"},
+		},
+		{
+			name: "outlier dropped",
+			providers: []forex.Client{
+				stubClient{name: "a", rate: 150}, stubClient{name: "b", rate: 15}, stubClient{name: "c", rate: 150.2},
+			},
+			maxDeviation: 1,
+			wantRate:     150.1,
+			wantSources:  []string{"a", "c"},
+		},
+		{
+			name: "failing provider ignored",
+			providers: []forex.Client{
+				stubClient{name: "a", rate: 150}, stubClient{name: "b", err: errors.New("down")},
+			},
+			maxDeviation: 1,
+			wantRate:     150,
+			wantSources:  []string{"a"},
+		},
+		{
+			name: "no deviation limit",
+			providers: []forex.Client{
+				stubClient{name: "a", rate: 100}, stubClient{name: "b", rate: 200},
+			},
+			wantRate:    150,
+			wantSources: []string{"a", "b"},
+		},
+		{
+			name: "disagreement",
+			providers: []forex.Client{
+				stubClient{name: "a", rate: 100}, stubClient{name: "b", rate: 200},
+			},
+			maxDeviation: 1,
+			wantErr:      true,
+		},
+		{
+			name: "all failing",
+			providers: []forex.Client{
+				stubClient{name: "a", err: errors.New("down")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := forex.NewAggregator(tt.maxDeviation, tt.providers...)
+
+			quote, err := forex.GetQuote(context.Background(), c, "USD", "JPY")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", quote)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if quote.Rate != tt.wantRate {
+				t.Errorf("expected rate %f, got %f", tt.wantRate, quote.Rate)
+			}
+			if len(quote.Sources) != len(tt.wantSources) {
+				t.Fatalf("expected sources %v, got %v", tt.wantSources, quote.Sources)
+			}
+			for i := range tt.wantSources {
+				if quote.Sources[i] != tt.wantSources[i] {
+					t.Errorf("expected sources %v, got %v", tt.wantSources, quote.Sources)
+				}
+			}
+		})
+	}
+}
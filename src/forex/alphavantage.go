@@ -11,27 +11,55 @@ import (
 
 	"github.com/pkg/errors"
 
+	"p2p-check/src/client/httpclient"
 	"p2p-check/src/logger"
 )
 
 const (
-	AlphavantageHost   = "https://www.alphavantage.co"
-	AlphavantageAPIKEY = "1R4TFWPP0H5DT2CC"
+	AlphavantageName = "alphavantage"
+
+	AlphavantageHost = "https://www.alphavantage.co"
 )
 
-type Alphavantage struct{}
+type alphavantageResponse struct {
+	Rate struct {
+		From string `json:"1. From_Currency Code"`
+		To   string `json:"3. To_Currency Code"`
+		Rate string `json:"5. Exchange Rate"`
+	} `json:"Realtime Currency Exchange Rate"`
+	Error string `json:"Error Message"`
+	// Note : sent instead of the rate when the API call frequency is exceeded
+	Note string `json:"Note"`
+}
+
+type Alphavantage struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient httpclient.Client
+}
+
+func (av *Alphavantage) Name() string {
+	return AlphavantageName
+}
+
+func (av *Alphavantage) GetCurrentFxRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	url := fmt.Sprintf("%s/query?function=CURRENCY_EXCHANGE_RATE&from_currency=%s&to_currency=%s&apikey=%s",
+		av.BaseURL, fromCurrency, toCurrency, av.APIKey)
 
-func (av *Alphavantage) GetCurrentFxRate(_ context.Context, fromCurrency, toCurrency string) (float64, error) {
-	resp, err := http.Get(
-		fmt.Sprintf("%s/query?function=CURRENCY_EXCHANGE_RATE&from_currency=%s&to_currency=%s&apikey=%s",
-			AlphavantageHost, fromCurrency, toCurrency, AlphavantageAPIKEY))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot create request")
+	}
+
+	resp, err := av.HTTPClient.Do(httpReq)
 	if err != nil {
 		return 0, err
 	}
+	defer resp.Body.Close()
 
-	var respBytes []byte
-	if resp.Body != nil {
-		respBytes, _ = io.ReadAll(resp.Body)
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot read response body")
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -42,23 +70,17 @@ func (av *Alphavantage) GetCurrentFxRate(_ context.Context, fromCurrency, toCurr
 		return 0, errors.New("status code != 200")
 	}
 
-	type r struct {
-		Rate struct {
-			From string `json:"1. From_Currency Code"`
-			To   string `json:"3. To_Currency Code"`
-			Rate string `json:"5. Exchange Rate"`
-		} `json:"Realtime Currency Exchange Rate"`
-		Error string `json:"Error Message"`
+	var rateResp alphavantageResponse
+	if err = json.Unmarshal(respBytes, &rateResp); err != nil {
+		logger.Default.WithField("resp", string(respBytes)).Error("invalid service response")
+		return 0, errors.Wrap(err, "invalid service response")
 	}
 
-	var rateResp r
-	_ = json.Unmarshal(respBytes, &rateResp)
-
-	if rateResp.Error != "" {
+	if rateResp.Error != "" || rateResp.Note != "" {
 		logger.Default.
 			WithField("resp", string(respBytes)).
-			Error(rateResp.Error)
-		return 0, errors.Wrap(errors.New(rateResp.Error), "rate APi returned error")
+			Error(rateResp.Error + rateResp.Note)
+		return 0, errors.Wrap(errors.New(rateResp.Error+rateResp.Note), "rate APi returned error")
 	}
 
 	rateFloat, err := strconv.ParseFloat(rateResp.Rate.Rate, 64)
@@ -76,6 +98,10 @@ func (av *Alphavantage) GetMaxRate() time.Duration {
 	return time.Minute / 5
 }
 
-func NewAlphavantage() Client {
-	return &Alphavantage{}
+func NewAlphavantage(baseURL, apiKey string, httpClient httpclient.Client) Client {
+	return &Alphavantage{
+		BaseURL:    baseURL,
+		APIKey:     apiKey,
+		HTTPClient: httpClient,
+	}
 }
@@ -0,0 +1,65 @@
+package forex_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"p2p-check/src/forex"
+	"p2p-check/src/internal/testserver"
+)
+
+func TestAlphavantage_GetCurrentFxRate(t *testing.T) {
+	queryChan := make(chan map[string]string, 1)
+	server := testserver.New(t, "/query", http.StatusOK, testserver.Fixture("alphavantage_rate.json", queryChan))
+	c := forex.NewAlphavantage(server.URL, "test-key", server.Client())
+
+	rate, err := c.GetCurrentFxRate(context.Background(), "USD", "JPY")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rate != 151.234 {
+		t.Errorf("expected rate 151.234, got %f", rate)
+	}
+
+	query := <-queryChan
+	if query["function"] != "CURRENCY_EXCHANGE_RATE" || query["from_currency"] != "USD" ||
+		query["to_currency"] != "JPY" || query["apikey"] != "test-key" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestAlphavantage_GetCurrentFxRate_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		fixture    string
+	}{
+		{name: "non 200", statusCode: http.StatusInternalServerError, fixture: "alphavantage_rate.json"},
+		{name: "error message", statusCode: http.StatusOK, fixture: "alphavantage_error.json"},
+		{name: "rate limited", statusCode: http.StatusOK, fixture: "alphavantage_note.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testserver.New(t, "/query", tt.statusCode, testserver.Fixture(tt.fixture, nil))
+			c := forex.NewAlphavantage(server.URL, "test-key", server.Client())
+
+			if _, err := c.GetCurrentFxRate(context.Background(), "USD", "JPY"); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestAlphavantage_GetCurrentFxRate_Canceled(t *testing.T) {
+	server := testserver.New(t, "/query", http.StatusOK, testserver.Fixture("alphavantage_rate.json", nil))
+	c := forex.NewAlphavantage(server.URL, "test-key", server.Client())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.GetCurrentFxRate(ctx, "USD", "JPY"); err == nil {
+		t.Fatal("expected error on canceled context")
+	}
+}
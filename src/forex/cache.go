@@ -0,0 +1,123 @@
+package forex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"p2p-check/src/logger"
+)
+
+// Cache : caches the rates of Client by currency pair. Rates younger than TTL are served without calling Client, and
+// rates younger than MaxStaleness are served when Client fails
+type Cache struct {
+	Client       Client
+	TTL          time.Duration
+	MaxStaleness time.Duration
+
+	lock   *sync.Mutex
+	quotes map[string]Quote
+}
+
+func (c *Cache) Name() string {
+	return c.Client.Name()
+}
+
+func (c *Cache) GetMaxRate() time.Duration {
+	return c.Client.GetMaxRate()
+}
+
+func (c *Cache) GetCurrentFxRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	quote, err := GetQuote(ctx, c, fromCurrency, toCurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	return quote.Rate, nil
+}
+
+// GetQuotes : only the currencies not cached, or cached for longer than TTL, are fetched from Client
+func (c *Cache) GetQuotes(ctx context.Context, fromCurrency string, toCurrencies []string) (map[string]Quote, error) {
+	quotes := make(map[string]Quote, len(toCurrencies))
+	missing := make([]string, 0, len(toCurrencies))
+	for _, toCurrency := range toCurrencies {
+		currency := strings.ToUpper(toCurrency)
+
+		if cached, ok := c.get(pairKey(fromCurrency, currency)); ok && cached.Age() < c.TTL {
+			quotes[currency] = cached
+		} else {
+			missing = append(missing, currency)
+		}
+	}
+
+	if len(missing) == 0 {
+		return quotes, nil
+	}
+
+	fetched, err := GetQuotes(ctx, c.Client, fromCurrency, missing)
+	if err != nil {
+		return c.staleQuotes(fromCurrency, missing, quotes, err)
+	}
+
+	for currency, quote := range fetched {
+		c.set(pairKey(fromCurrency, currency), quote)
+		quotes[currency] = quote
+	}
+
+	return quotes, nil
+}
+
+// staleQuotes : adds to quotes the cached quotes of currencies, if all of them are younger than MaxStaleness.
+// Otherwise, err is returned
+func (c *Cache) staleQuotes(
+	fromCurrency string, currencies []string, quotes map[string]Quote, err error,
+) (map[string]Quote, error) {
+	for _, currency := range currencies {
+		key := pairKey(fromCurrency, currency)
+
+		cached, ok := c.get(key)
+		if !ok || cached.Age() >= c.MaxStaleness {
+			return nil, err
+		}
+
+		logger.Default.
+			WithField("pair", key).
+			WithField("age", cached.Age().String()).
+			Warn("serving cached fx rate: " + err.Error())
+		quotes[currency] = cached
+	}
+
+	return quotes, nil
+}
+
+func (c *Cache) get(key string) (Quote, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	quote, ok := c.quotes[key]
+	return quote, ok
+}
+
+func (c *Cache) set(key string, quote Quote) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.quotes[key] = quote
+}
+
+// pairKey : cache key of a currency pair
+func pairKey(fromCurrency, toCurrency string) string {
+	return fmt.Sprintf("%s/%s", strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency))
+}
+
+func NewCache(client Client, ttl, maxStaleness time.Duration) Client {
+	return &Cache{
+		Client:       client,
+		TTL:          ttl,
+		MaxStaleness: maxStaleness,
+		lock:         &sync.Mutex{},
+		quotes:       make(map[string]Quote),
+	}
+}
@@ -0,0 +1,72 @@
+package forex_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"p2p-check/src/forex"
+)
+
+func TestCache_GetQuote(t *testing.T) {
+	down, calls := false, 0
+	provider := flakyClient{stubClient: stubClient{name: "a", rate: 150}, down: &down, calls: &calls}
+
+	c := forex.NewCache(provider, time.Hour, 2*time.Hour)
+
+	first, err := forex.GetQuote(context.Background(), c, "USD", "JPY")
+	if err != nil || first.Rate != 150 {
+		t.Fatalf("expected quote, got %+v (%v)", first, err)
+	}
+
+	// served from the cache
+	second, err := forex.GetQuote(context.Background(), c, "usd", "jpy")
+	if err != nil || calls != 1 || !second.FetchedAt.Equal(first.FetchedAt) {
+		t.Errorf("expected cached quote, got %+v (%v) after %d calls", second, err, calls)
+	}
+
+	// other pairs are cached separately
+	if _, err = forex.GetQuote(context.Background(), c, "USD", "EUR"); err != nil || calls != 2 {
+		t.Errorf("expected a new call for another pair, got %d calls (%v)", calls, err)
+	}
+}
+
+func TestCache_GetQuote_Stale(t *testing.T) {
+	down, calls := false, 0
+	provider := flakyClient{stubClient: stubClient{name: "a", rate: 150}, down: &down, calls: &calls}
+
+	// rates expire immediately, but can be served for an hour on errors
+	c := forex.NewCache(provider, 0, time.Hour)
+
+	if _, err := forex.GetQuote(context.Background(), c, "USD", "JPY"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	down = true
+	quote, err := forex.GetQuote(context.Background(), c, "USD", "JPY")
+	if err != nil || quote.Rate != 150 || calls != 2 {
+		t.Errorf("expected stale quote, got %+v (%v) after %d calls", quote, err, calls)
+	}
+
+	// nothing cached for this pair
+	if _, err = forex.GetQuote(context.Background(), c, "USD", "EUR"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCache_GetQuote_TooStale(t *testing.T) {
+	down, calls := false, 0
+	provider := flakyClient{stubClient: stubClient{name: "a", rate: 150}, down: &down, calls: &calls}
+
+	c := forex.NewCache(provider, 0, time.Nanosecond)
+
+	if _, err := forex.GetQuote(context.Background(), c, "USD", "JPY"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	down = true
+	if _, err := forex.GetQuote(context.Background(), c, "USD", "JPY"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,141 @@
+package forex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"p2p-check/src/logger"
+)
+
+// FailoverName : name of the Failover provider
+const FailoverName = "failover"
+
+// Failover : tries providers in priority order, returning the first rate obtained. Failing providers are tried last
+// until their Cooldown is over
+type Failover struct {
+	Providers []Client
+	Cooldown  time.Duration
+	// OnSwitch : called, if not nil, when the provider serving rates changes. err is the error of the previous
+	// provider, nil if it simply recovered
+	OnSwitch func(previous, current string, err error)
+
+	lock           *sync.Mutex
+	unhealthyUntil map[string]time.Time
+	// current : provider that served the last rate
+	current string
+	// lastErr : last error of current
+	lastErr error
+}
+
+func (f *Failover) Name() string {
+	return FailoverName
+}
+
+// GetMaxRate : any provider can be called, so the slowest one must be waited for
+func (f *Failover) GetMaxRate() time.Duration {
+	var maxRate time.Duration
+	for _, p := range f.Providers {
+		if r := p.GetMaxRate(); r > maxRate {
+			maxRate = r
+		}
+	}
+
+	return maxRate
+}
+
+func (f *Failover) GetCurrentFxRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
+	quote, err := GetQuote(ctx, f, fromCurrency, toCurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	return quote.Rate, nil
+}
+
+func (f *Failover) GetQuotes(ctx context.Context, fromCurrency string, toCurrencies []string) (map[string]Quote, error) {
+	errs := make([]string, 0, len(f.Providers))
+
+	for _, p := range f.orderedProviders() {
+		quotes, err := GetQuotes(ctx, p, fromCurrency, toCurrencies)
+		if err != nil {
+			logger.Default.WithField("provider", p.Name()).Warn("fx provider failed: " + err.Error())
+
+			f.markUnhealthy(p, err)
+			errs = append(errs, fmt.Sprintf("%s: %s", p.Name(), err))
+			continue
+		}
+
+		f.markHealthy(p)
+		return quotes, nil
+	}
+
+	return nil, fmt.Errorf("all fx providers failed: %s", strings.Join(errs, "; "))
+}
+
+// orderedProviders : healthy providers first, then the ones cooling down, both in priority order
+func (f *Failover) orderedProviders() []Client {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := time.Now()
+	healthy := make([]Client, 0, len(f.Providers))
+	unhealthy := make([]Client, 0, len(f.Providers))
+	for _, p := range f.Providers {
+		if until, ok := f.unhealthyUntil[p.Name()]; ok && now.Before(until) {
+			unhealthy = append(unhealthy, p)
+		} else {
+			healthy = append(healthy, p)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+func (f *Failover) markUnhealthy(p Client, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.unhealthyUntil[p.Name()] = time.Now().Add(f.Cooldown)
+	if p.Name() == f.current {
+		f.lastErr = err
+	}
+}
+
+func (f *Failover) markHealthy(p Client) {
+	f.lock.Lock()
+	delete(f.unhealthyUntil, p.Name())
+
+	previous, previousErr := f.current, f.lastErr
+	f.current, f.lastErr = p.Name(), nil
+	f.lock.Unlock()
+
+	if previous == "" || previous == p.Name() {
+		return
+	}
+
+	logger.Default.WithField("previous", previous).WithField("current", p.Name()).Warn("fx provider switched")
+
+	if f.OnSwitch != nil {
+		f.OnSwitch(previous, p.Name(), previousErr)
+	}
+}
+
+func NewFailover(cooldown time.Duration, onSwitch func(previous, current string, err error), providers ...Client) Client {
+	f := &Failover{
+		Providers:      providers,
+		Cooldown:       cooldown,
+		OnSwitch:       onSwitch,
+		lock:           &sync.Mutex{},
+		unhealthyUntil: make(map[string]time.Time),
+	}
+
+	// so that falling back on the very first call is reported as well
+	if len(providers) > 0 {
+		f.current = providers[0].Name()
+	}
+
+	return f
+}
@@ -0,0 +1,103 @@
+package forex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"p2p-check/src/forex"
+)
+
+// flakyClient : stubClient failing while down is true
+type flakyClient struct {
+	stubClient
+	down  *bool
+	calls *int
+}
+
+func (f flakyClient) GetCurrentFxRate(ctx context.Context, from, to string) (float64, error) {
+	*f.calls++
+	if *f.down {
+		return 0, errors.New("down")
+	}
+
+	return f.stubClient.GetCurrentFxRate(ctx, from, to)
+}
+
+func TestFailover_GetQuote(t *testing.T) {
+	primaryDown, primaryCalls := false, 0
+	primary := flakyClient{stubClient: stubClient{name: "primary", rate: 150}, down: &primaryDown, calls: &primaryCalls}
+	secondary := stubClient{name: "secondary", rate: 151}
+
+	type switchEvent struct {
+		previous, current string
+		failed            bool
+	}
+	var switches []switchEvent
+	onSwitch := func(previous, current string, err error) {
+		switches = append(switches, switchEvent{previous: previous, current: current, failed: err != nil})
+	}
+
+	c := forex.NewFailover(time.Hour, onSwitch, primary, secondary)
+
+	quote, err := forex.GetQuote(context.Background(), c, "USD", "JPY")
+	if err != nil || quote.Rate != 150 || quote.Sources[0] != "primary" {
+		t.Fatalf("expected primary quote, got %+v (%v)", quote, err)
+	}
+
+	primaryDown = true
+	quote, err = forex.GetQuote(context.Background(), c, "USD", "JPY")
+	if err != nil || quote.Rate != 151 || quote.Sources[0] != "secondary" {
+		t.Fatalf("expected secondary quote, got %+v (%v)", quote, err)
+	}
+	if len(switches) != 1 || switches[0] != (switchEvent{previous: "primary", current: "secondary", failed: true}) {
+		t.Errorf("unexpected switches %+v", switches)
+	}
+
+	// primary is cooling down, so it is not tried before secondary
+	primaryDown, primaryCalls = false, 0
+	if _, err = forex.GetQuote(context.Background(), c, "USD", "JPY"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if primaryCalls != 0 {
+		t.Errorf("expected primary to be skipped during its cool-down, called %d times", primaryCalls)
+	}
+}
+
+func TestFailover_GetQuote_Recovery(t *testing.T) {
+	primaryDown, primaryCalls := true, 0
+	primary := flakyClient{stubClient: stubClient{name: "primary", rate: 150}, down: &primaryDown, calls: &primaryCalls}
+	secondary := stubClient{name: "secondary", rate: 151}
+
+	var recovered bool
+	onSwitch := func(previous, current string, err error) {
+		recovered = current == "primary" && err == nil
+	}
+
+	// no cool-down, so primary is tried first at every call
+	c := forex.NewFailover(0, onSwitch, primary, secondary)
+
+	if quote, err := forex.GetQuote(context.Background(), c, "USD", "JPY"); err != nil || quote.Rate != 151 {
+		t.Fatalf("expected secondary quote, got %+v (%v)", quote, err)
+	}
+
+	primaryDown = false
+	if quote, err := forex.GetQuote(context.Background(), c, "USD", "JPY"); err != nil || quote.Rate != 150 {
+		t.Fatalf("expected primary quote, got %+v (%v)", quote, err)
+	}
+	if !recovered {
+		t.Error("expected recovery to be reported")
+	}
+}
+
+func TestFailover_GetQuote_AllFailing(t *testing.T) {
+	c := forex.NewFailover(time.Hour, nil,
+		stubClient{name: "a", err: errors.New("down")},
+		stubClient{name: "b", err: errors.New("quota exceeded")},
+	)
+
+	if _, err := forex.GetQuote(context.Background(), c, "USD", "JPY"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -16,6 +16,8 @@ import (
 )
 
 const (
+	FastForexName = "fastforex"
+
 	APIURL           = "https://api.fastforex.io"
 	FetchOneEndpoint = "fetch-one"
 	FetchAllEndpoint = "fetch-all"
@@ -38,17 +40,22 @@ type clientFetchAllResponse struct {
 }
 
 type FastForexClient struct {
+	BaseURL    string
 	APIKey     string
 	HTTPClient httpclient.Client
 }
 
+func (i *FastForexClient) Name() string {
+	return FastForexName
+}
+
 func (i *FastForexClient) GetMaxRate() time.Duration {
 	return time.Minute / 2
 }
 
 func (i *FastForexClient) GetCurrentFxRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error) {
 	url := fmt.Sprintf("%s/%s?from=%s&to=%s&api_key=%s",
-		APIURL,
+		i.BaseURL,
 		FetchOneEndpoint,
 		fromCurrency,
 		toCurrency,
@@ -70,6 +77,39 @@ func (i *FastForexClient) GetCurrentFxRate(ctx context.Context, fromCurrency, to
 	return rate, nil
 }
 
+// GetCurrentFxRates : rates of toCurrencies, taken from all the fromCurrency rates fetched in a single call
+func (i *FastForexClient) GetCurrentFxRates(
+	ctx context.Context, fromCurrency string, toCurrencies []string,
+) (map[string]float64, error) {
+	url := fmt.Sprintf("%s/%s?from=%s&api_key=%s",
+		i.BaseURL,
+		FetchAllEndpoint,
+		fromCurrency,
+		i.APIKey,
+	)
+
+	var response clientFetchAllResponse
+	if err := i.fetch(ctx, url, &response); err != nil {
+		return nil, errors.Wrap(err, "cannot fetch rates")
+	}
+
+	rates := make(map[string]float64, len(toCurrencies))
+	for _, toCurrency := range toCurrencies {
+		currency := strings.ToUpper(toCurrency)
+
+		rate, ok := response.Results[currency]
+		if !ok {
+			logger.Default.WithField("currency", currency).WithField("response", response).Error("result is incorrect")
+
+			return nil, errors.Errorf("result is incorrect, %s missing", currency)
+		}
+
+		rates[currency] = rate
+	}
+
+	return rates, nil
+}
+
 func (i *FastForexClient) fetch(ctx context.Context, url string, resp interface{}) error {
 	httpReq, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 
@@ -102,8 +142,9 @@ func (i *FastForexClient) fetch(ctx context.Context, url string, resp interface{
 	return nil
 }
 
-func NewFastForexClient(apiKey string, httpClient httpclient.Client) Client {
+func NewFastForexClient(baseURL, apiKey string, httpClient httpclient.Client) Client {
 	return &FastForexClient{
+		BaseURL:    baseURL,
 		APIKey:     apiKey,
 		HTTPClient: httpClient,
 	}
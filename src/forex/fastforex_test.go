@@ -0,0 +1,91 @@
+package forex_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"p2p-check/src/forex"
+	"p2p-check/src/internal/testserver"
+)
+
+func TestFastForexClient_GetCurrentFxRate(t *testing.T) {
+	queryChan := make(chan map[string]string, 1)
+	server := testserver.New(t, "/fetch-one", http.StatusOK, testserver.Fixture("fastforex_fetch_one.json", queryChan))
+	c := forex.NewFastForexClient(server.URL, "test-key", server.Client())
+
+	rate, err := c.GetCurrentFxRate(context.Background(), "USD", "JPY")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rate != 151.2 {
+		t.Errorf("expected rate 151.2, got %f", rate)
+	}
+
+	query := <-queryChan
+	if query["from"] != "USD" || query["to"] != "JPY" || query["api_key"] != "test-key" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestFastForexClient_GetCurrentFxRate_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		fixture    string
+	}{
+		{name: "non 200", statusCode: http.StatusUnauthorized, fixture: "fastforex_error.json"},
+		{name: "missing result", statusCode: http.StatusOK, fixture: "fastforex_error.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testserver.New(t, "/fetch-one", tt.statusCode, testserver.Fixture(tt.fixture, nil))
+			c := forex.NewFastForexClient(server.URL, "test-key", server.Client())
+
+			if _, err := c.GetCurrentFxRate(context.Background(), "USD", "JPY"); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestFastForexClient_GetQuote(t *testing.T) {
+	// a single currency is fetched on its own, the server only answers /fetch-one
+	server := testserver.New(t, "/fetch-one", http.StatusOK, testserver.Fixture("fastforex_fetch_one.json", nil))
+	c := forex.NewFastForexClient(server.URL, "test-key", server.Client())
+
+	quote, err := forex.GetQuote(context.Background(), c, "USD", "jpy")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if quote.Rate != 151.2 || len(quote.Sources) != 1 {
+		t.Errorf("unexpected quote %+v", quote)
+	}
+}
+
+func TestFastForexClient_GetCurrentFxRates(t *testing.T) {
+	queryChan := make(chan map[string]string, 1)
+	server := testserver.New(t, "/fetch-all", http.StatusOK, testserver.Fixture("fastforex_fetch_all.json", queryChan))
+	c := forex.NewFastForexClient(server.URL, "test-key", server.Client())
+
+	quotes, err := forex.GetQuotes(context.Background(), c, "USD", []string{"JPY", "eur", "TWD"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(quotes) != 3 || quotes["JPY"].Rate != 151.2 || quotes["EUR"].Rate != 0.9351 || quotes["TWD"].Rate != 32.571 {
+		t.Errorf("unexpected quotes %+v", quotes)
+	}
+
+	// a single call for all the currencies
+	if query := <-queryChan; query["from"] != "USD" || query["api_key"] != "test-key" {
+		t.Errorf("unexpected query %v", query)
+	}
+	if len(queryChan) != 0 {
+		t.Errorf("expected a single call")
+	}
+
+	if _, err = forex.GetQuotes(context.Background(), c, "USD", []string{"JPY", "XXX"}); err == nil {
+		t.Error("expected error for missing currency")
+	}
+}
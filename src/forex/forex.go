@@ -2,10 +2,91 @@ package forex
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
 type Client interface {
 	GetCurrentFxRate(ctx context.Context, fromCurrency, toCurrency string) (float64, error)
 	GetMaxRate() time.Duration
+	// Name : provider name, shown in notifications
+	Name() string
+}
+
+// BatchClient : Client able to fetch the rates of several currencies in a single call
+type BatchClient interface {
+	Client
+	// GetCurrentFxRates : rates by upper-case currency
+	GetCurrentFxRates(ctx context.Context, fromCurrency string, toCurrencies []string) (map[string]float64, error)
+}
+
+// Quote : fx rate, with the providers it comes from
+type Quote struct {
+	Rate    float64
+	Sources []string
+	// FetchedAt : when the rate was obtained from the providers
+	FetchedAt time.Time
+}
+
+// Age : time elapsed since the rate was fetched
+func (q Quote) Age() time.Duration {
+	return time.Since(q.FetchedAt)
+}
+
+// QuoteClient : Client able to tell which providers a rate comes from
+type QuoteClient interface {
+	Client
+	// GetQuotes : quotes by upper-case currency
+	GetQuotes(ctx context.Context, fromCurrency string, toCurrencies []string) (map[string]Quote, error)
+}
+
+// GetQuote : quote from c, whose only source is c itself unless c is a QuoteClient
+func GetQuote(ctx context.Context, c Client, fromCurrency, toCurrency string) (Quote, error) {
+	quotes, err := GetQuotes(ctx, c, fromCurrency, []string{toCurrency})
+	if err != nil {
+		return Quote{}, err
+	}
+
+	return quotes[strings.ToUpper(toCurrency)], nil
+}
+
+// GetQuotes : quotes from c by upper-case currency, fetched in a single call if c is a BatchClient and there are
+// several currencies. Fails unless every currency could be quoted
+func GetQuotes(ctx context.Context, c Client, fromCurrency string, toCurrencies []string) (map[string]Quote, error) {
+	if qc, ok := c.(QuoteClient); ok {
+		return qc.GetQuotes(ctx, fromCurrency, toCurrencies)
+	}
+
+	rates := make(map[string]float64, len(toCurrencies))
+	if bc, ok := c.(BatchClient); ok && len(toCurrencies) > 1 {
+		var err error
+		if rates, err = bc.GetCurrentFxRates(ctx, fromCurrency, toCurrencies); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, toCurrency := range toCurrencies {
+			rate, err := c.GetCurrentFxRate(ctx, fromCurrency, toCurrency)
+			if err != nil {
+				return nil, err
+			}
+
+			rates[strings.ToUpper(toCurrency)] = rate
+		}
+	}
+
+	fetchedAt := time.Now()
+	quotes := make(map[string]Quote, len(toCurrencies))
+	for _, toCurrency := range toCurrencies {
+		currency := strings.ToUpper(toCurrency)
+
+		rate, ok := rates[currency]
+		if !ok {
+			return nil, fmt.Errorf("%s rate not returned by %s", currency, c.Name())
+		}
+
+		quotes[currency] = Quote{Rate: rate, Sources: []string{c.Name()}, FetchedAt: fetchedAt}
+	}
+
+	return quotes, nil
 }
@@ -0,0 +1,35 @@
+package forex_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"p2p-check/src/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+
+	os.Exit(m.Run())
+}
+
+// stubClient : provider always returning rate, or err if set
+type stubClient struct {
+	name string
+	rate float64
+	err  error
+}
+
+func (s stubClient) GetCurrentFxRate(context.Context, string, string) (float64, error) {
+	return s.rate, s.err
+}
+
+func (s stubClient) GetMaxRate() time.Duration {
+	return time.Second
+}
+
+func (s stubClient) Name() string {
+	return s.name
+}
@@ -0,0 +1,60 @@
+package forex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"p2p-check/src/client/httpclient"
+)
+
+// ProviderOptions : settings of a provider built by New
+type ProviderOptions struct {
+	APIKey string
+	// BaseURL : provider API URL. Empty means the default one
+	BaseURL    string
+	HTTPClient httpclient.Client
+}
+
+// provider : how to build a registered provider
+type provider struct {
+	defaultBaseURL string
+	newClient      func(baseURL, apiKey string, httpClient httpclient.Client) Client
+}
+
+// providers : available providers, by name
+var providers = map[string]provider{
+	FastForexName:    {defaultBaseURL: APIURL, newClient: NewFastForexClient},
+	AlphavantageName: {defaultBaseURL: AlphavantageHost, newClient: NewAlphavantage},
+}
+
+// ProviderNames : names of the providers New can build
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// New : builds the provider called name
+func New(name string, opts ProviderOptions) (Client, error) {
+	p, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("forex provider '%s' not supported, available ones are %s",
+			name, strings.Join(ProviderNames(), ","))
+	}
+
+	if opts.APIKey == "" {
+		return nil, fmt.Errorf("missing API key for forex provider '%s'", name)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = p.defaultBaseURL
+	}
+
+	return p.newClient(baseURL, opts.APIKey, opts.HTTPClient), nil
+}
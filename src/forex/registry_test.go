@@ -0,0 +1,36 @@
+package forex_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"p2p-check/src/forex"
+	"p2p-check/src/internal/testserver"
+)
+
+func TestNew(t *testing.T) {
+	server := testserver.New(t, "/query", http.StatusOK, testserver.Fixture("alphavantage_rate.json", nil))
+
+	c, err := forex.New("Alphavantage", forex.ProviderOptions{
+		APIKey:     "test-key",
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if c.Name() != forex.AlphavantageName {
+		t.Errorf("expected %s, got %s", forex.AlphavantageName, c.Name())
+	}
+	if _, err = c.GetCurrentFxRate(context.Background(), "USD", "JPY"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if _, err = forex.New("unknown", forex.ProviderOptions{APIKey: "test-key"}); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+	if _, err = forex.New(forex.FastForexName, forex.ProviderOptions{}); err == nil {
+		t.Error("expected error for missing API key")
+	}
+}
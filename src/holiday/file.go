@@ -0,0 +1,111 @@
+package holiday
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// icsDateLayout : layout of all-day dates in ICS files
+const icsDateLayout = "20060102"
+
+// ParseDates : dates must follow DateLayout
+func ParseDates(dates []string) (Dates, error) {
+	res := Dates{}
+	for _, d := range dates {
+		t, err := time.Parse(DateLayout, d)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid date '%s'", d)
+		}
+		res.Add(t)
+	}
+
+	return res, nil
+}
+
+// LoadFile : load holidays from an ICS (.ics) file, or from a JSON file containing an array of dates following
+// DateLayout
+func LoadFile(path string) (Dates, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read holiday file")
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics":
+		return parseICS(content)
+	case ".json":
+		var dates []string
+		if err = json.Unmarshal(content, &dates); err != nil {
+			return nil, errors.Wrap(err, "invalid holiday file")
+		}
+
+		return ParseDates(dates)
+	default:
+		return nil, errors.Errorf("unsupported holiday file '%s'", path)
+	}
+}
+
+// parseICS : every event is considered a holiday, from DTSTART included to DTEND excluded
+func parseICS(content []byte) (Dates, error) {
+	dates := Dates{}
+
+	var start, end time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "BEGIN:VEVENT":
+			start, end = time.Time{}, time.Time{}
+		case strings.HasPrefix(line, "DTSTART"):
+			t, err := parseICSDate(line)
+			if err != nil {
+				return nil, err
+			}
+			start = t
+		case strings.HasPrefix(line, "DTEND"):
+			t, err := parseICSDate(line)
+			if err != nil {
+				return nil, err
+			}
+			end = t
+		case line == "END:VEVENT":
+			if start.IsZero() {
+				continue
+			}
+
+			dates.Add(start)
+			for d := start.AddDate(0, 0, 1); d.Before(end); d = d.AddDate(0, 0, 1) {
+				dates.Add(d)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot read ICS content")
+	}
+
+	return dates, nil
+}
+
+// parseICSDate : parse lines like "DTSTART;VALUE=DATE:20240101" or "DTSTART:20240101T000000Z"
+func parseICSDate(line string) (time.Time, error) {
+	idx := strings.LastIndex(line, ":")
+	if idx < 0 || len(line) < idx+1+len(icsDateLayout) {
+		return time.Time{}, errors.Errorf("invalid ICS date '%s'", line)
+	}
+
+	t, err := time.Parse(icsDateLayout, line[idx+1:idx+1+len(icsDateLayout)])
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid ICS date '%s'", line)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,69 @@
+package holiday
+
+import (
+	"strings"
+	"time"
+)
+
+// DateLayout : layout of the dates used by this package
+const DateLayout = "2006-01-02"
+
+// Calendar : tells which days are holidays
+type Calendar interface {
+	// IsHoliday : true if the day of t, in t's location, is a holiday
+	IsHoliday(t time.Time) bool
+}
+
+// Dates : calendar made of a fixed set of days
+type Dates map[string]struct{}
+
+func (d Dates) IsHoliday(t time.Time) bool {
+	_, ok := d[t.Format(DateLayout)]
+	return ok
+}
+
+// Add : add the day of t
+func (d Dates) Add(t time.Time) {
+	d[t.Format(DateLayout)] = struct{}{}
+}
+
+// Union : a day is a holiday if it is one for any of the calendars
+type Union []Calendar
+
+func (u Union) IsHoliday(t time.Time) bool {
+	for _, c := range u {
+		if c.IsHoliday(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Registry : calendars by country code (e.g. "JP")
+type Registry map[string]Calendar
+
+// Get : calendar for country, case-insensitive
+func (r Registry) Get(country string) (Calendar, bool) {
+	c, ok := r[strings.ToUpper(country)]
+	return c, ok
+}
+
+// Add : add c to the calendars of country
+func (r Registry) Add(country string, c Calendar) {
+	country = strings.ToUpper(country)
+
+	if existing, ok := r[country]; ok {
+		r[country] = Union{existing, c}
+		return
+	}
+
+	r[country] = c
+}
+
+// NewRegistry : registry including the built-in calendars
+func NewRegistry() Registry {
+	return Registry{
+		"JP": NewJapan(),
+	}
+}
@@ -0,0 +1,88 @@
+package holiday_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"p2p-check/src/holiday"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func TestJapan_IsHoliday(t *testing.T) {
+	jp := holiday.NewJapan()
+
+	holidays := []time.Time{
+		date(2024, time.January, 1),
+		date(2024, time.January, 3),   // bank closure
+		date(2024, time.January, 8),   // Coming of Age Day
+		date(2024, time.February, 12), // substitute for National Foundation Day
+		date(2024, time.March, 20),    // Vernal Equinox Day
+		date(2024, time.May, 6),       // substitute for Children's Day
+		date(2024, time.July, 15),     // Marine Day
+		date(2024, time.September, 16),
+		date(2024, time.September, 22), // Autumnal Equinox Day
+		date(2024, time.September, 23), // substitute
+		date(2024, time.October, 14),   // Sports Day
+		date(2024, time.November, 4),   // substitute for Culture Day
+		date(2026, time.September, 22), // citizens' holiday
+		date(2024, time.December, 31),  // bank closure
+	}
+	for _, d := range holidays {
+		if !jp.IsHoliday(d) {
+			t.Errorf("expected %s to be a holiday", d.Format(holiday.DateLayout))
+		}
+	}
+
+	workdays := []time.Time{
+		date(2024, time.January, 4),
+		date(2024, time.March, 21),
+		date(2024, time.May, 7),
+		date(2024, time.September, 24),
+	}
+	for _, d := range workdays {
+		if jp.IsHoliday(d) {
+			t.Errorf("expected %s not to be a holiday", d.Format(holiday.DateLayout))
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	ics, err := holiday.LoadFile(filepath.Join("testdata", "holidays.ics"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, d := range []time.Time{date(2024, time.December, 30), date(2024, time.December, 31), date(2024, time.August, 15)} {
+		if !ics.IsHoliday(d) {
+			t.Errorf("expected %s to be an ICS holiday", d.Format(holiday.DateLayout))
+		}
+	}
+	if ics.IsHoliday(date(2025, time.January, 1)) {
+		t.Error("DTEND should be excluded")
+	}
+
+	json, err := holiday.LoadFile(filepath.Join("testdata", "holidays.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !json.IsHoliday(date(2024, time.August, 13)) || json.IsHoliday(date(2024, time.August, 15)) {
+		t.Errorf("unexpected JSON holidays %v", json)
+	}
+}
+
+func TestRegistry_Add(t *testing.T) {
+	r := holiday.NewRegistry()
+	extra, _ := holiday.ParseDates([]string{"2024-08-13"})
+	r.Add("jp", extra)
+
+	jp, ok := r.Get("JP")
+	if !ok {
+		t.Fatal("expected JP calendar")
+	}
+	if !jp.IsHoliday(date(2024, time.August, 13)) || !jp.IsHoliday(date(2024, time.January, 1)) {
+		t.Error("expected both built-in and added holidays")
+	}
+}
@@ -0,0 +1,105 @@
+package holiday
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Japan : Japanese national holidays, following the rules in force since 2020, plus the days banks are closed for
+// the new year (December 31st to January 3rd)
+type Japan struct {
+	lock *sync.Mutex
+
+	// years : holidays by year, computed on demand
+	years map[int]Dates
+}
+
+func (j *Japan) IsHoliday(t time.Time) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	dates, ok := j.years[t.Year()]
+	if !ok {
+		dates = japaneseHolidays(t.Year())
+		j.years[t.Year()] = dates
+	}
+
+	return dates.IsHoliday(t)
+}
+
+func japaneseHolidays(year int) Dates {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	national := []time.Time{
+		date(time.January, 1),                           // New Year's Day
+		nthMonday(year, time.January, 2),                // Coming of Age Day
+		date(time.February, 11),                         // National Foundation Day
+		date(time.February, 23),                         // Emperor's Birthday
+		date(time.March, equinoxDay(year, 20.8431)),     // Vernal Equinox Day
+		date(time.April, 29),                            // Showa Day
+		date(time.May, 3),                               // Constitution Memorial Day
+		date(time.May, 4),                               // Greenery Day
+		date(time.May, 5),                               // Children's Day
+		nthMonday(year, time.July, 3),                   // Marine Day
+		date(time.August, 11),                           // Mountain Day
+		nthMonday(year, time.September, 3),              // Respect for the Aged Day
+		date(time.September, equinoxDay(year, 23.2488)), // Autumnal Equinox Day
+		nthMonday(year, time.October, 2),                // Sports Day
+		date(time.November, 3),                          // Culture Day
+		date(time.November, 23),                         // Labor Thanksgiving Day
+	}
+
+	dates := Dates{}
+	for _, d := range national {
+		dates.Add(d)
+	}
+
+	for _, d := range national {
+		// substitute holiday: a holiday on Sunday moves to the next non-holiday day
+		if d.Weekday() == time.Sunday {
+			next := d.AddDate(0, 0, 1)
+			for dates.IsHoliday(next) {
+				next = next.AddDate(0, 0, 1)
+			}
+			dates.Add(next)
+		}
+
+		// citizens' holiday: a day between two holidays is a holiday too
+		if between := d.AddDate(0, 0, 1); !dates.IsHoliday(between) && between.Weekday() != time.Sunday &&
+			dates.IsHoliday(d.AddDate(0, 0, 2)) {
+			dates.Add(between)
+		}
+	}
+
+	// banks are closed for the new year
+	dates.Add(date(time.January, 2))
+	dates.Add(date(time.January, 3))
+	dates.Add(date(time.December, 31))
+
+	return dates
+}
+
+// nthMonday : n-th Monday (starting from 1) of month
+func nthMonday(year int, month time.Month, n int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(time.Monday) - int(first.Weekday()) + 7) % 7
+
+	return first.AddDate(0, 0, offset+(n-1)*7)
+}
+
+// equinoxDay : day of the equinox, approximation valid from 1980 to 2099. base is 20.8431 for the vernal equinox and
+// 23.2488 for the autumnal one
+func equinoxDay(year int, base float64) int {
+	y := float64(year - 1980)
+	return int(math.Floor(base + 0.242194*y - math.Floor(y/4)))
+}
+
+func NewJapan() Calendar {
+	return &Japan{
+		lock:  &sync.Mutex{},
+		years: make(map[int]Dates),
+	}
+}
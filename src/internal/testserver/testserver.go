@@ -0,0 +1,65 @@
+package testserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FixtureFunc : name of the fixture answering r, in the testdata directory of the calling package. An empty name
+// answers with a 404
+type FixtureFunc func(r *http.Request) string
+
+// New : returns a server answering requests to path with statusCode and the fixture chosen by fixture. Requests to
+// other paths get a 404. The server is closed at the end of the test
+func New(t *testing.T, path string, statusCode int, fixture FixtureFunc) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		name := fixture(r)
+		if name == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		body, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("cannot read fixture: %s", err)
+			return
+		}
+
+		w.WriteHeader(statusCode)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Fixture : answers every request with the fixture name. Received query parameters are sent to queryChan, if not nil
+func Fixture(name string, queryChan chan map[string]string) FixtureFunc {
+	return func(r *http.Request) string {
+		if queryChan != nil {
+			queryChan <- Query(r)
+		}
+
+		return name
+	}
+}
+
+// Query : query parameters of r, keeping the first value of each
+func Query(r *http.Request) map[string]string {
+	query := make(map[string]string)
+	for k := range r.URL.Query() {
+		query[k] = r.URL.Query().Get(k)
+	}
+
+	return query
+}
@@ -0,0 +1,49 @@
+package marketplace
+
+import (
+	"context"
+
+	"p2p-check/src/client/binance"
+	"p2p-check/src/offer"
+)
+
+type Binance struct {
+	client *binance.Client
+}
+
+func (b *Binance) Name() string {
+	return binance.Venue
+}
+
+func (b *Binance) ListOffers(ctx context.Context, q Query) ([]offer.Offer, error) {
+	opts := binance.ListOptions{
+		TradeType:      binance.TradeType(q.Side),
+		MaxPages:       q.MaxPages,
+		MaxAds:         q.MaxOffers,
+		PayTypes:       q.PaymentMethods,
+		Countries:      q.Countries,
+		ProMerchantAds: q.ProMerchantOnly,
+		TransAmount:    q.TransAmount,
+	}
+	if q.MerchantOnly {
+		opts.PublisherType = "merchant"
+	}
+
+	advs, err := b.client.ListP2PAdvs(ctx, q.Asset, q.Fiat, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := make([]offer.Offer, 0, len(advs))
+	for i := range advs {
+		offers = append(offers, advs[i].ToOffer())
+	}
+
+	return offers, nil
+}
+
+func NewBinance(client *binance.Client) Client {
+	return &Binance{
+		client: client,
+	}
+}
@@ -0,0 +1,36 @@
+package marketplace
+
+import (
+	"context"
+
+	"p2p-check/src/offer"
+)
+
+// Query : what to look for. Venues ignore the fields they don't support
+type Query struct {
+	Asset string
+	Fiat  string
+	Side  offer.Side
+	// PaymentMethods : payment method identifiers (e.g. "BANK", "LINEPay"). Empty means any
+	PaymentMethods []string
+	// TransAmount : fiat amount we intend to trade. Only offers that can fill it are returned. 0 means any
+	TransAmount float64
+	// MerchantOnly : only return offers of merchants
+	MerchantOnly bool
+	// MaxPages : max amount of result pages to fetch, for venues supporting pagination
+	MaxPages int
+	// MaxOffers : max amount of offers to return. 0 means no limit
+	MaxOffers int
+
+	// Countries : advertiser countries. Empty means any. Binance only
+	Countries []string
+	// ProMerchantOnly : only return offers of pro merchants. Binance only
+	ProMerchantOnly bool
+}
+
+// Client : a P2P marketplace
+type Client interface {
+	// Name : name of the venue, as shown in notifications
+	Name() string
+	ListOffers(ctx context.Context, q Query) ([]offer.Offer, error)
+}
@@ -0,0 +1,81 @@
+package marketplace
+
+import (
+	"context"
+
+	"p2p-check/src/client/okx"
+	"p2p-check/src/offer"
+)
+
+type OKX struct {
+	client *okx.Client
+}
+
+func (o *OKX) Name() string {
+	return okx.Venue
+}
+
+// ListOffers : OKX returns all the ads in a single page and only supports one payment method per request, so the
+// remaining filters are applied here
+func (o *OKX) ListOffers(ctx context.Context, q Query) ([]offer.Offer, error) {
+	// our side is the opposite of the advertiser's one
+	opts := okx.ListOptions{
+		Side:         okx.SideSell,
+		MerchantOnly: q.MerchantOnly,
+	}
+	if q.Side == offer.SideSell {
+		opts.Side = okx.SideBuy
+	}
+
+	ads, err := o.client.ListAds(ctx, q.Asset, q.Fiat, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := make([]offer.Offer, 0, len(ads))
+	for i := range ads {
+		of := ads[i].ToOffer()
+
+		if q.TransAmount > 0 && !canTrade(of, q.TransAmount) {
+			continue
+		}
+
+		if len(q.PaymentMethods) > 0 && !hasPaymentMethod(of, q.PaymentMethods) {
+			continue
+		}
+
+		offers = append(offers, of)
+		if q.MaxOffers > 0 && len(offers) >= q.MaxOffers {
+			break
+		}
+	}
+
+	return offers, nil
+}
+
+// canTrade : true if amount is within the limits of o. Ads without a valid max amount are only limited by their min
+func canTrade(o offer.Offer, amount float64) bool {
+	if amount < o.MinAmount {
+		return false
+	}
+
+	return o.MaxAmount <= 0 || amount <= o.MaxAmount
+}
+
+func hasPaymentMethod(o offer.Offer, identifiers []string) bool {
+	for _, m := range o.PaymentMethods {
+		for _, identifier := range identifiers {
+			if m.Identifier == identifier {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func NewOKX(client *okx.Client) Client {
+	return &OKX{
+		client: client,
+	}
+}
@@ -0,0 +1,120 @@
+package marketplace_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"p2p-check/src/client/okx"
+	"p2p-check/src/internal/testserver"
+	"p2p-check/src/logger"
+	"p2p-check/src/marketplace"
+	"p2p-check/src/offer"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+
+	os.Exit(m.Run())
+}
+
+func TestOKX_ListOffers(t *testing.T) {
+	// okx_books_sell.json ads:
+	// 0: bank, 10000-300000
+	// 1: bank and LINE Pay, 10000-100000
+	// 2: PayPay, 10000-300000
+	// 3: bank, 50000 min and no max
+	// 4: LINE Pay, 10000 min and unparsable max
+	tests := []struct {
+		name    string
+		q       marketplace.Query
+		wantIDs []string
+	}{
+		{
+			name:    "no filters",
+			wantIDs: []string{"23041100000", "23041100001", "23041100002", "23041100003", "23041100004"},
+		},
+		{
+			name:    "amount above some max amounts",
+			q:       marketplace.Query{TransAmount: 200000},
+			wantIDs: []string{"23041100000", "23041100002", "23041100003", "23041100004"},
+		},
+		{
+			name:    "amount below some min amounts",
+			q:       marketplace.Query{TransAmount: 20000},
+			wantIDs: []string{"23041100000", "23041100001", "23041100002", "23041100004"},
+		},
+		{
+			name:    "single payment method",
+			q:       marketplace.Query{PaymentMethods: []string{"BANK"}},
+			wantIDs: []string{"23041100000", "23041100001", "23041100003"},
+		},
+		{
+			name:    "several payment methods",
+			q:       marketplace.Query{PaymentMethods: []string{"LINEPay", "PayPay"}},
+			wantIDs: []string{"23041100001", "23041100002", "23041100004"},
+		},
+		{
+			name:    "max offers",
+			q:       marketplace.Query{MaxOffers: 2},
+			wantIDs: []string{"23041100000", "23041100001"},
+		},
+		{
+			// only offers passing the filters count towards the limit
+			name:    "max offers after filters",
+			q:       marketplace.Query{TransAmount: 200000, PaymentMethods: []string{"BANK"}, MaxOffers: 2},
+			wantIDs: []string{"23041100000", "23041100003"},
+		},
+	}
+
+	server := testserver.New(
+		t, "/v3/c2c/tradingOrders/books", http.StatusOK, testserver.Fixture("okx_books_sell.json", nil),
+	)
+	mc := marketplace.NewOKX(okx.NewClient(server.URL, server.Client()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.q
+			q.Asset, q.Fiat, q.Side = "USDT", "JPY", offer.SideBuy
+
+			offers, err := mc.ListOffers(context.Background(), q)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			ids := make([]string, 0, len(offers))
+			for _, o := range offers {
+				ids = append(ids, o.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("expected offers %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestOKX_ListOffers_Query(t *testing.T) {
+	queryChan := make(chan map[string]string, 1)
+	server := testserver.New(
+		t, "/v3/c2c/tradingOrders/books", http.StatusOK, testserver.Fixture("okx_books_sell.json", queryChan),
+	)
+	mc := marketplace.NewOKX(okx.NewClient(server.URL, server.Client()))
+
+	// we sell, so we look for the ads of buyers
+	_, err := mc.ListOffers(context.Background(), marketplace.Query{
+		Asset:        "USDT",
+		Fiat:         "JPY",
+		Side:         offer.SideSell,
+		MerchantOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	query := <-queryChan
+	if query["side"] != string(okx.SideBuy) || query["userType"] != "certified" || query["baseCurrency"] != "usdt" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
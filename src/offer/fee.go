@@ -0,0 +1,55 @@
+package offer
+
+import "math"
+
+// Fee : cost of moving fiat with a payment method (e.g. bank transfer fee)
+type Fee struct {
+	// Fixed : fiat amount charged for each transfer
+	Fixed float64
+	// Percentage : percentage of the transferred fiat amount
+	Percentage float64
+}
+
+// Amount : fiat fee for transferring fiatAmount
+func (f Fee) Amount(fiatAmount float64) float64 {
+	return f.Fixed + fiatAmount*f.Percentage/100
+}
+
+// TradableAmount : max fiat amount that can be traded on o with a single trade
+func (o Offer) TradableAmount() float64 {
+	amount := o.AvailableAmount * o.Price
+	if o.MaxAmount > 0 {
+		amount = math.Min(amount, o.MaxAmount)
+	}
+
+	return amount
+}
+
+// Trade : fiat and asset amounts actually exchanged when trading fiatAmount on o with method, the venue commission and
+// the payment method fee included.
+// For SideBuy, fiat is what we pay and asset what we receive; for SideSell, fiat is what we receive and asset what we
+// send.
+func (o Offer) Trade(fiatAmount float64, method PaymentMethod, fee Fee) (fiat, asset float64) {
+	commissionRate := o.CommissionRate
+	if method.CommissionRate > 0 {
+		commissionRate = method.CommissionRate
+	}
+
+	asset = fiatAmount / o.Price
+
+	if o.Side == SideSell {
+		return fiatAmount - fee.Amount(fiatAmount), asset * (1 + commissionRate)
+	}
+
+	return fiatAmount + fee.Amount(fiatAmount), asset * (1 - commissionRate)
+}
+
+// EffectivePrice : price of o once commission and fee are included, when trading fiatAmount with method
+func (o Offer) EffectivePrice(fiatAmount float64, method PaymentMethod, fee Fee) float64 {
+	fiat, asset := o.Trade(fiatAmount, method, fee)
+	if asset <= 0 {
+		return 0
+	}
+
+	return fiat / asset
+}
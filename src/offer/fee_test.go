@@ -0,0 +1,44 @@
+package offer_test
+
+import (
+	"math"
+	"testing"
+
+	"p2p-check/src/offer"
+)
+
+func TestOffer_EffectivePrice(t *testing.T) {
+	bank := offer.PaymentMethod{Identifier: "BANK"}
+	line := offer.PaymentMethod{Identifier: "LINEPay", CommissionRate: 0.002}
+	bankFee := offer.Fee{Fixed: 440}
+
+	buy := offer.Offer{Side: offer.SideBuy, Price: 150, CommissionRate: 0.001}
+	tests := []struct {
+		name   string
+		o      offer.Offer
+		amount float64
+		method offer.PaymentMethod
+		fee    offer.Fee
+		want   float64
+	}{
+		{name: "no fees", o: offer.Offer{Side: offer.SideBuy, Price: 150}, amount: 15000, method: bank, want: 150},
+		{name: "buy bank fee", o: buy, amount: 15000, method: bank, fee: bankFee, want: 15440 / (100 * 0.999)},
+		{name: "buy method commission", o: buy, amount: 15000, method: line, want: 15000 / (100 * 0.998)},
+		{
+			name:   "sell bank fee",
+			o:      offer.Offer{Side: offer.SideSell, Price: 150, CommissionRate: 0.001},
+			amount: 15000,
+			method: bank,
+			fee:    offer.Fee{Fixed: 440, Percentage: 1},
+			want:   (15000 - 440 - 150) / (100 * 1.001),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.EffectivePrice(tt.amount, tt.method, tt.fee); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected %f, got %f", tt.want, got)
+			}
+		})
+	}
+}
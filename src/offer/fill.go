@@ -0,0 +1,84 @@
+package offer
+
+import (
+	"math"
+	"sort"
+)
+
+// FillLeg : part of a FillPlan to be traded on a single offer
+type FillLeg struct {
+	Offer Offer
+	// FiatAmount : fiat amount to trade on Offer
+	FiatAmount float64
+	// AssetAmount : asset amount FiatAmount corresponds to
+	AssetAmount float64
+}
+
+// FillPlan : how to trade a fiat amount across several offers
+type FillPlan struct {
+	Legs []FillLeg
+	// FiatAmount : total fiat amount that can be traded. Less than the requested one if offers are not enough
+	FiatAmount float64
+	// AssetAmount : total asset amount FiatAmount corresponds to
+	AssetAmount float64
+	// Complete : true if the whole requested fiat amount can be traded
+	Complete bool
+}
+
+// EffectivePrice : volume-weighted price of the plan. 0 if nothing can be traded
+func (p FillPlan) EffectivePrice() float64 {
+	if p.AssetAmount == 0 {
+		return 0
+	}
+
+	return p.FiatAmount / p.AssetAmount
+}
+
+// Fill : plan how to trade fiatAmount across offers, starting from the best price for side.
+// Per-trade limits and available amounts of each offer are honored: offers that cannot take at least their own min
+// amount are skipped.
+func Fill(offers []Offer, side Side, fiatAmount float64) FillPlan {
+	sorted := make([]Offer, len(offers))
+	copy(sorted, offers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if side == SideSell {
+			return sorted[i].Price > sorted[j].Price
+		}
+
+		return sorted[i].Price < sorted[j].Price
+	})
+
+	var plan FillPlan
+	remaining := fiatAmount
+
+	for _, o := range sorted {
+		if remaining <= 0 {
+			break
+		}
+
+		if o.Price <= 0 {
+			continue
+		}
+
+		take := math.Min(remaining, o.TradableAmount())
+
+		if take <= 0 || take < o.MinAmount {
+			continue
+		}
+
+		leg := FillLeg{
+			Offer:       o,
+			FiatAmount:  take,
+			AssetAmount: take / o.Price,
+		}
+		plan.Legs = append(plan.Legs, leg)
+		plan.FiatAmount += leg.FiatAmount
+		plan.AssetAmount += leg.AssetAmount
+		remaining -= take
+	}
+
+	// tolerate rounding errors
+	plan.Complete = remaining <= fiatAmount*1e-9
+
+	return plan
+}
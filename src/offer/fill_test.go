@@ -0,0 +1,69 @@
+package offer_test
+
+import (
+	"math"
+	"testing"
+
+	"p2p-check/src/offer"
+)
+
+func TestFill(t *testing.T) {
+	offers := []offer.Offer{
+		{ID: "expensive", Price: 152, AvailableAmount: 10000, MinAmount: 1000, MaxAmount: 1000000},
+		{ID: "cheap", Price: 150, AvailableAmount: 1000, MinAmount: 1000, MaxAmount: 1000000},
+		// min amount too high for what is left to fill
+		{ID: "high-min", Price: 150.5, AvailableAmount: 10000, MinAmount: 200000, MaxAmount: 1000000},
+		{ID: "capped", Price: 151, AvailableAmount: 10000, MinAmount: 1000, MaxAmount: 100000},
+	}
+
+	plan := offer.Fill(offers, offer.SideBuy, 300000)
+	if !plan.Complete {
+		t.Fatalf("expected complete plan, got %+v", plan)
+	}
+
+	wantLegs := []struct {
+		id   string
+		fiat float64
+	}{
+		{id: "cheap", fiat: 150000},
+		{id: "capped", fiat: 100000},
+		{id: "expensive", fiat: 50000},
+	}
+	if len(plan.Legs) != len(wantLegs) {
+		t.Fatalf("expected %d legs, got %+v", len(wantLegs), plan.Legs)
+	}
+	for i, want := range wantLegs {
+		if plan.Legs[i].Offer.ID != want.id || plan.Legs[i].FiatAmount != want.fiat {
+			t.Errorf("leg %d: expected %s for %f, got %s for %f",
+				i, want.id, want.fiat, plan.Legs[i].Offer.ID, plan.Legs[i].FiatAmount)
+		}
+	}
+
+	wantPrice := 300000 / (150000/150.0 + 100000/151.0 + 50000/152.0)
+	if math.Abs(plan.EffectivePrice()-wantPrice) > 1e-9 {
+		t.Errorf("expected effective price %f, got %f", wantPrice, plan.EffectivePrice())
+	}
+}
+
+func TestFill_Sell(t *testing.T) {
+	offers := []offer.Offer{
+		{ID: "low", Price: 149, AvailableAmount: 10000, MaxAmount: 1000000},
+		{ID: "high", Price: 151, AvailableAmount: 100, MaxAmount: 1000000},
+	}
+
+	plan := offer.Fill(offers, offer.SideSell, 20000)
+	if !plan.Complete || plan.Legs[0].Offer.ID != "high" || plan.Legs[0].FiatAmount != 15100 {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+}
+
+func TestFill_NotEnoughLiquidity(t *testing.T) {
+	offers := []offer.Offer{
+		{ID: "small", Price: 150, AvailableAmount: 10, MaxAmount: 1000000},
+	}
+
+	plan := offer.Fill(offers, offer.SideBuy, 300000)
+	if plan.Complete || plan.FiatAmount != 1500 {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+}
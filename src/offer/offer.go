@@ -0,0 +1,79 @@
+package offer
+
+import "time"
+
+// Side : side of the trade, from our point of view
+type Side string
+
+const (
+	// SideBuy : we buy the asset, the advertiser sells it
+	SideBuy Side = "BUY"
+	// SideSell : we sell the asset, the advertiser buys it
+	SideSell Side = "SELL"
+)
+
+type PaymentMethod struct {
+	// Identifier : venue specific identifier of the payment method (e.g. "BANK")
+	Identifier string
+	// Name : human-readable name
+	Name string
+	// CommissionRate : ratio [0, 1] charged by the venue when trading with this payment method. Overrides
+	// Offer.CommissionRate if > 0
+	CommissionRate float64
+}
+
+type Advertiser struct {
+	// ID : venue specific user identifier, stable across nickname changes
+	ID       string
+	Nickname string
+	// IsMerchant : true if the venue verified the advertiser as a merchant
+	IsMerchant bool
+	// MonthOrderCount : orders completed in the last 30 days. Negative if unknown
+	MonthOrderCount int
+	// MonthFinishRate : ratio [0, 1] of orders completed in the last 30 days. Negative if unknown
+	MonthFinishRate float64
+	// PositiveRate : ratio [0, 1] of positive feedbacks. Negative if unknown
+	PositiveRate float64
+	// TotalOrderCount : orders completed since registration. Negative if unknown
+	TotalOrderCount int
+	// TotalFinishRate : ratio [0, 1] of orders completed since registration. Negative if unknown
+	TotalFinishRate float64
+	// RegisteredAt : zero if unknown
+	RegisteredAt time.Time
+	// LastActiveAt : zero if unknown
+	LastActiveAt time.Time
+}
+
+// Offer : a P2P ad, independent of the venue it comes from
+type Offer struct {
+	// Venue : name of the marketplace the offer comes from
+	Venue string
+	// ID : venue specific ad identifier
+	ID    string
+	Side  Side
+	Asset string
+	Fiat  string
+	// Price : fiat amount for 1 unit of Asset
+	Price float64
+	// AvailableAmount : Asset amount that can still be traded
+	AvailableAmount float64
+	// MinAmount : min fiat amount for a single trade
+	MinAmount float64
+	// MaxAmount : max fiat amount for a single trade, already capped by what the advertiser can currently afford if
+	// the venue provides it. 0 if unknown
+	MaxAmount float64
+	// Tradable : false if the venue reports that the offer cannot be traded at the moment
+	Tradable bool
+	// CommissionRate : ratio [0, 1] charged by the venue on the traded asset amount
+	CommissionRate float64
+	PaymentMethods []PaymentMethod
+	// Remarks : free text conditions written by the advertiser
+	Remarks string
+	// AutoReplyMessage : message automatically sent by the advertiser when an order is created
+	AutoReplyMessage string
+	Advertiser       Advertiser
+	// CreatedAt : zero if unknown
+	CreatedAt time.Time
+	// UpdatedAt : zero if unknown
+	UpdatedAt time.Time
+}
@@ -0,0 +1,97 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"p2p-check/src/client/httpclient"
+	"p2p-check/src/logger"
+)
+
+const (
+	BinanceSpotHost = "https://api.binance.com"
+
+	// BinanceQuoteAsset : Binance spot has no USD pairs, USDT is used as its proxy
+	BinanceQuoteAsset = "USDT"
+)
+
+type binanceTickerResponse struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+}
+
+type BinanceSpot struct {
+	BaseURL    string
+	HTTPClient httpclient.Client
+}
+
+func (b *BinanceSpot) GetUSDPrice(ctx context.Context, asset string) (float64, error) {
+	if strings.EqualFold(asset, BinanceQuoteAsset) {
+		return 1, nil
+	}
+
+	query := url.Values{}
+	query.Set("symbol", strings.ToUpper(asset)+BinanceQuoteAsset)
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/api/v3/ticker/price?%s", b.BaseURL, query.Encode()),
+		nil,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid request")
+	}
+	httpReq.Header.Add("accept", "application/json")
+
+	httpResp, err := b.HTTPClient.Do(httpReq)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot fetch price")
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot read response body")
+	}
+
+	var r binanceTickerResponse
+	_ = json.Unmarshal(body, &r)
+
+	if httpResp.StatusCode != http.StatusOK {
+		logger.Default.
+			WithField("resp", string(body)).
+			WithField("status-code", httpResp.StatusCode).
+			Error("status code != 200")
+		return 0, errors.Errorf("status code != 200: %s", r.Msg)
+	}
+
+	p, err := strconv.ParseFloat(r.Price, 64)
+	if err != nil {
+		logger.Default.WithField("resp", string(body)).Error("invalid service response")
+		return 0, errors.Wrap(err, "invalid service response")
+	}
+
+	return p, nil
+}
+
+func (b *BinanceSpot) QuoteAsset() string {
+	return BinanceQuoteAsset
+}
+
+func NewBinanceSpot(baseURL string, httpClient httpclient.Client) Client {
+	return &BinanceSpot{
+		BaseURL:    baseURL,
+		HTTPClient: httpClient,
+	}
+}
@@ -0,0 +1,65 @@
+package price_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"p2p-check/src/internal/testserver"
+	"p2p-check/src/price"
+)
+
+func TestBinanceSpot_GetUSDPrice(t *testing.T) {
+	queryChan := make(chan map[string]string, 1)
+	server := testserver.New(
+		t, "/api/v3/ticker/price", http.StatusOK, testserver.Fixture("binance_ticker.json", queryChan),
+	)
+	c := price.NewBinanceSpot(server.URL, server.Client())
+
+	p, err := c.GetUSDPrice(context.Background(), "btc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p != 63251.12 {
+		t.Errorf("expected price 63251.12, got %f", p)
+	}
+	if query := <-queryChan; query["symbol"] != "BTCUSDT" {
+		t.Errorf("unexpected query %v", query)
+	}
+
+	// the quote asset is not fetched
+	if p, err = c.GetUSDPrice(context.Background(), price.BinanceQuoteAsset); err != nil || p != 1 {
+		t.Errorf("expected 1 for the quote asset, got %f (%v)", p, err)
+	}
+	if len(queryChan) != 0 {
+		t.Error("unexpected call for the quote asset")
+	}
+}
+
+func TestBinanceSpot_GetUSDPrice_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		fixture    string
+		wantMsg    string
+	}{
+		{name: "non 200", statusCode: http.StatusBadRequest, fixture: "binance_invalid_symbol.json", wantMsg: "Invalid symbol."},
+		{name: "malformed price", statusCode: http.StatusOK, fixture: "binance_malformed_price.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testserver.New(t, "/api/v3/ticker/price", tt.statusCode, testserver.Fixture(tt.fixture, nil))
+			c := price.NewBinanceSpot(server.URL, server.Client())
+
+			_, err := c.GetUSDPrice(context.Background(), "BTC")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("expected error containing '%s', got %s", tt.wantMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"p2p-check/src/client/httpclient"
+	"p2p-check/src/logger"
+)
+
+const (
+	KrakenHost = "https://api.kraken.com"
+
+	// krakenUnknownPairError : error returned for pairs Kraken does not list
+	krakenUnknownPairError = "EQuery:Unknown asset pair"
+)
+
+type krakenTickerResponse struct {
+	Error  []string `json:"error"`
+	Result map[string]struct {
+		// C : last trade closed, [price, lot volume]
+		C []string `json:"c"`
+	} `json:"result"`
+}
+
+// Kraken : Kraken has real USD markets for the main stablecoins, so it can be used to detect depegs
+type Kraken struct {
+	BaseURL    string
+	HTTPClient httpclient.Client
+}
+
+func (k *Kraken) GetPeg(ctx context.Context, stablecoin string) (float64, error) {
+	query := url.Values{}
+	query.Set("pair", strings.ToUpper(stablecoin)+"USD")
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/0/public/Ticker?%s", k.BaseURL, query.Encode()),
+		nil,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid request")
+	}
+	httpReq.Header.Add("accept", "application/json")
+
+	httpResp, err := k.HTTPClient.Do(httpReq)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot fetch peg")
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot read response body")
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		logger.Default.
+			WithField("resp", string(body)).
+			WithField("status-code", httpResp.StatusCode).
+			Error("status code != 200")
+		return 0, errors.New("status code != 200")
+	}
+
+	var r krakenTickerResponse
+	if err = json.Unmarshal(body, &r); err != nil {
+		logger.Default.WithField("resp", string(body)).Error("invalid service response")
+		return 0, errors.Wrap(err, "invalid service response")
+	}
+
+	for _, e := range r.Error {
+		if strings.HasPrefix(e, krakenUnknownPairError) {
+			return 0, errors.Wrapf(ErrUnknownPeg, "no %s/USD market on Kraken", strings.ToUpper(stablecoin))
+		}
+	}
+	if len(r.Error) > 0 {
+		return 0, errors.Errorf("peg API returned error: %s", strings.Join(r.Error, ","))
+	}
+
+	// Kraken renames pairs (e.g. USDTUSD -> USDTZUSD), but only the requested one is returned
+	for _, ticker := range r.Result {
+		if len(ticker.C) == 0 {
+			break
+		}
+
+		peg, err := strconv.ParseFloat(ticker.C[0], 64)
+		if err != nil {
+			return 0, errors.Wrap(err, "cannot convert peg to float")
+		}
+
+		return peg, nil
+	}
+
+	logger.Default.WithField("resp", string(body)).Error("result is incorrect")
+	return 0, errors.New("result is incorrect")
+}
+
+func NewKraken(baseURL string, httpClient httpclient.Client) ReferenceClient {
+	return &Kraken{
+		BaseURL:    baseURL,
+		HTTPClient: httpClient,
+	}
+}
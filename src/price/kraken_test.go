@@ -0,0 +1,58 @@
+package price_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"p2p-check/src/internal/testserver"
+	"p2p-check/src/price"
+)
+
+func TestKraken_GetPeg(t *testing.T) {
+	queryChan := make(chan map[string]string, 1)
+	server := testserver.New(t, "/0/public/Ticker", http.StatusOK, testserver.Fixture("kraken_ticker.json", queryChan))
+	c := price.NewKraken(server.URL, server.Client())
+
+	// the result key is USDTZUSD, while USDTUSD is requested
+	peg, err := c.GetPeg(context.Background(), "usdt")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if peg != 0.99975 {
+		t.Errorf("expected peg 0.99975, got %f", peg)
+	}
+	if query := <-queryChan; query["pair"] != "USDTUSD" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestKraken_GetPeg_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		fixture     string
+		wantUnknown bool
+	}{
+		{name: "non 200", statusCode: http.StatusBadGateway, fixture: "kraken_ticker.json"},
+		{name: "unknown pair", statusCode: http.StatusOK, fixture: "kraken_error.json", wantUnknown: true},
+		{name: "error array", statusCode: http.StatusOK, fixture: "kraken_unavailable.json"},
+		{name: "empty close", statusCode: http.StatusOK, fixture: "kraken_empty_close.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testserver.New(t, "/0/public/Ticker", tt.statusCode, testserver.Fixture(tt.fixture, nil))
+			c := price.NewKraken(server.URL, server.Client())
+
+			_, err := c.GetPeg(context.Background(), "USDT")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if errors.Is(err, price.ErrUnknownPeg) != tt.wantUnknown {
+				t.Errorf("expected unknown peg %t, got %s", tt.wantUnknown, err)
+			}
+		})
+	}
+}
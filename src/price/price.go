@@ -0,0 +1,42 @@
+package price
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownPeg : the ReferenceClient has no USD market for the stablecoin, so its peg cannot be known
+var ErrUnknownPeg = errors.New("unknown peg")
+
+// Client : provides spot prices for crypto assets
+type Client interface {
+	// GetUSDPrice : price of 1 unit of asset, in USD. Implementations may use a stablecoin as a proxy for USD, see
+	// QuoteAsset
+	GetUSDPrice(ctx context.Context, asset string) (float64, error)
+	// QuoteAsset : asset prices are actually expressed in. "USD" if no proxy is used
+	QuoteAsset() string
+}
+
+// ReferenceClient : provides the actual USD price of stablecoins, so that depegs can be taken into account
+type ReferenceClient interface {
+	// GetPeg : price of 1 unit of stablecoin, in USD. Fails with ErrUnknownPeg if stablecoin cannot be priced at all
+	GetPeg(ctx context.Context, stablecoin string) (float64, error)
+}
+
+// stablecoins : assets pegged to USD, whose price is considered 1 USD without asking any Client
+var stablecoins = map[string]struct{}{
+	"USDT":  {},
+	"USDC":  {},
+	"FDUSD": {},
+	"BUSD":  {},
+	"TUSD":  {},
+	"DAI":   {},
+}
+
+// IsStablecoin : true if asset is pegged to USD
+func IsStablecoin(asset string) bool {
+	_, ok := stablecoins[strings.ToUpper(asset)]
+	return ok
+}
@@ -0,0 +1,14 @@
+package price_test
+
+import (
+	"os"
+	"testing"
+
+	"p2p-check/src/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"p2p-check/src/holiday"
+)
+
+// clockLayout : layout of TimeRange bounds
+const clockLayout = "15:04"
+
+// TimeRange : bounds are "HH:MM", both included. If To is before From, the range spans midnight
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Schedule : when something is available. Empty fields mean no restriction
+type Schedule struct {
+	// Weekdays : e.g. "monday" or "mon", case-insensitive
+	Weekdays   []string    `json:"weekdays,omitempty"`
+	TimeRanges []TimeRange `json:"timeRanges,omitempty"`
+	// Timezone : IANA name (e.g. "Asia/Tokyo") Weekdays and TimeRanges refer to. Defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+	// Holidays : country code (e.g. "JP") of the holiday calendar. The schedule is closed on its holidays
+	Holidays string `json:"holidays,omitempty"`
+}
+
+// IsOpen : true if t falls into the schedule. calendars is used to look up Holidays, and can be nil if not set.
+// Compile the schedule instead when checking it several times
+func (s *Schedule) IsOpen(t time.Time, calendars holiday.Registry) (bool, error) {
+	c, err := s.Compile(calendars)
+	if err != nil {
+		return false, err
+	}
+
+	return c.IsOpen(t), nil
+}
+
+// Compiled : validated Schedule, with its timezone, holiday calendar, weekdays and time ranges resolved
+type Compiled struct {
+	location *time.Location
+	// calendar : nil if the schedule is not closed on holidays
+	calendar holiday.Calendar
+	// weekdays : empty means any
+	weekdays map[time.Weekday]struct{}
+	ranges   []minuteRange
+}
+
+// minuteRange : TimeRange as minutes since midnight
+type minuteRange struct {
+	from int
+	to   int
+}
+
+// Compile : validate s, looking up Holidays in calendars (can be nil if not set)
+func (s *Schedule) Compile(calendars holiday.Registry) (*Compiled, error) {
+	c := &Compiled{
+		location: time.UTC,
+		weekdays: make(map[time.Weekday]struct{}, len(s.Weekdays)),
+		ranges:   make([]minuteRange, 0, len(s.TimeRanges)),
+	}
+
+	if s.Timezone != "" {
+		var err error
+		if c.location, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, errors.Wrapf(err, "invalid timezone '%s'", s.Timezone)
+		}
+	}
+
+	if s.Holidays != "" {
+		calendar, ok := calendars.Get(s.Holidays)
+		if !ok {
+			return nil, errors.Errorf("unknown holiday calendar '%s'", s.Holidays)
+		}
+		c.calendar = calendar
+	}
+
+	for _, d := range s.Weekdays {
+		weekday, err := parseWeekday(d)
+		if err != nil {
+			return nil, err
+		}
+		c.weekdays[weekday] = struct{}{}
+	}
+
+	for _, r := range s.TimeRanges {
+		mr, err := r.parse()
+		if err != nil {
+			return nil, err
+		}
+		c.ranges = append(c.ranges, mr)
+	}
+
+	return c, nil
+}
+
+// IsOpen : true if t falls into the schedule
+func (c *Compiled) IsOpen(t time.Time) bool {
+	t = t.In(c.location)
+
+	if c.calendar != nil && c.calendar.IsHoliday(t) {
+		return false
+	}
+
+	if len(c.weekdays) > 0 {
+		if _, ok := c.weekdays[t.Weekday()]; !ok {
+			return false
+		}
+	}
+
+	if len(c.ranges) == 0 {
+		return true
+	}
+
+	minuteOfDay := t.Hour()*60 + t.Minute()
+	for _, r := range c.ranges {
+		if r.contains(minuteOfDay) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r TimeRange) parse() (minuteRange, error) {
+	from, err := parseClock(r.From)
+	if err != nil {
+		return minuteRange{}, err
+	}
+
+	to, err := parseClock(r.To)
+	if err != nil {
+		return minuteRange{}, err
+	}
+
+	return minuteRange{from: from, to: to}, nil
+}
+
+func (r minuteRange) contains(minuteOfDay int) bool {
+	if r.from <= r.to {
+		return minuteOfDay >= r.from && minuteOfDay <= r.to
+	}
+
+	// spanning midnight
+	return minuteOfDay >= r.from || minuteOfDay <= r.to
+}
+
+// parseClock : minutes since midnight of "HH:MM"
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid time '%s'", clock)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseWeekday(day string) (time.Weekday, error) {
+	day = strings.ToLower(strings.TrimSpace(day))
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if day == name || day == name[:3] {
+			return d, nil
+		}
+	}
+
+	return 0, errors.Errorf("invalid weekday '%s'", day)
+}
@@ -0,0 +1,69 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"p2p-check/src/holiday"
+	"p2p-check/src/schedule"
+)
+
+func TestSchedule_IsOpen(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	bank := schedule.Schedule{
+		Weekdays:   []string{"mon", "Tuesday", "wed", "thu", "fri"},
+		TimeRanges: []schedule.TimeRange{{From: "06:00", To: "14:30"}},
+		Timezone:   "Asia/Tokyo",
+	}
+	bankJP := bank
+	bankJP.Holidays = "JP"
+	night := schedule.Schedule{
+		TimeRanges: []schedule.TimeRange{{From: "22:00", To: "02:00"}},
+	}
+
+	tests := []struct {
+		name string
+		s    schedule.Schedule
+		t    time.Time
+		want bool
+	}{
+		{name: "weekday morning", s: bank, t: time.Date(2023, 4, 10, 6, 0, 0, 0, tokyo), want: true},
+		{name: "weekday end", s: bank, t: time.Date(2023, 4, 10, 14, 30, 59, 0, tokyo), want: true},
+		{name: "weekday after end", s: bank, t: time.Date(2023, 4, 10, 14, 31, 0, 0, tokyo), want: false},
+		{name: "weekend", s: bank, t: time.Date(2023, 4, 8, 10, 0, 0, 0, tokyo), want: false},
+		// 10:00 in Tokyo is still Sunday in UTC, but Monday in Tokyo
+		{name: "timezone", s: bank, t: time.Date(2023, 4, 9, 23, 0, 0, 0, time.UTC), want: true},
+		{name: "spanning midnight before", s: night, t: time.Date(2023, 4, 9, 23, 0, 0, 0, time.UTC), want: true},
+		{name: "spanning midnight after", s: night, t: time.Date(2023, 4, 9, 1, 0, 0, 0, time.UTC), want: true},
+		{name: "spanning midnight outside", s: night, t: time.Date(2023, 4, 9, 12, 0, 0, 0, time.UTC), want: false},
+		{name: "national holiday", s: bankJP, t: time.Date(2023, 5, 3, 10, 0, 0, 0, tokyo), want: false},
+		{name: "substitute holiday", s: bankJP, t: time.Date(2023, 1, 2, 10, 0, 0, 0, tokyo), want: false},
+		{name: "not a holiday", s: bankJP, t: time.Date(2023, 5, 8, 10, 0, 0, 0, tokyo), want: true},
+		{name: "empty", s: schedule.Schedule{}, t: time.Now(), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.IsOpen(tt.t, holiday.NewRegistry())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSchedule_IsOpen_Invalid(t *testing.T) {
+	for _, s := range []schedule.Schedule{
+		{Timezone: "Mars/Olympus"},
+		{Holidays: "XX"},
+		{Weekdays: []string{"funday"}},
+		{TimeRanges: []schedule.TimeRange{{From: "6am", To: "14:30"}}},
+	} {
+		if _, err := s.IsOpen(time.Now(), nil); err == nil {
+			t.Errorf("expected error for %+v", s)
+		}
+	}
+}